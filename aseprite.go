@@ -0,0 +1,177 @@
+package gogl
+
+/*
+	ASEPRITE
+
+	Loads a Sprite definition from a spritesheet JSON as exported by Aseprite
+	(File > Export Sprite Sheet, with "JSON Data" checked). Both the "Hash" and
+	"Array" export formats are supported.
+
+	When the sheet is a square grid of square tiles, the Sprite uses Divisions
+	like a hand-made spritesheet. Any other layout (e.g. Aseprite's default
+	horizontal strip, or packed sheets) gives a Sprite with UseAtlasRects, so
+	the shader needs to use the "tex_rect" uniform for those.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+type asepriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type asepriteFrame struct {
+	Filename string       `json:"filename"`
+	Frame    asepriteRect `json:"frame"`
+	Duration int          `json:"duration"`
+}
+
+type asepriteFrameTag struct {
+	Name string `json:"name"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+type asepriteFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string             `json:"image"`
+		Size      asepriteRect       `json:"size"`
+		FrameTags []asepriteFrameTag `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// Reads an Aseprite spritesheet JSON and returns a Sprite with TextureSource, Divisions (or UseAtlasRects),
+// AnimationFrames, FrameDurations and AnimationTags filled in. The texture itself is
+// loaded when the Sprite is added with DataObject.AddSprite().
+func LoadSpriteFromJSON(path string) (Sprite, error) {
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Sprite{}, err
+	}
+
+	var sheet asepriteFile
+	if err := json.Unmarshal(fileData, &sheet); err != nil {
+		return Sprite{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	frames, err := parseAsepriteFrames(sheet.Frames)
+	if err != nil {
+		return Sprite{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(frames) == 0 {
+		return Sprite{}, fmt.Errorf("%s: no frames found", path)
+	}
+	if sheet.Meta.Image == "" {
+		return Sprite{}, fmt.Errorf("%s: meta.image is not set", path)
+	}
+
+	if sheet.Meta.Size.W <= 0 || sheet.Meta.Size.H <= 0 {
+		return Sprite{}, fmt.Errorf("%s: meta.size is not set", path)
+	}
+
+	sprite := Sprite{
+		Name:          filepath.Base(path),
+		TextureSource: filepath.Join(filepath.Dir(path), sheet.Meta.Image), // image path is relative to the json
+		Scale:         1,
+		AnimationTags: make(map[string][2]int),
+	}
+
+	if tileSize, ok := asepriteGridTileSize(sheet, frames); ok {
+		sprite.Divisions = sheet.Meta.Size.W / tileSize
+		for _, frame := range frames {
+			// tex_y counts from the bottom of the sheet, as the texture is flipped for GL
+			column := float32(frame.Frame.X / tileSize)
+			row := float32(sprite.Divisions - 1 - frame.Frame.Y/tileSize)
+			sprite.AnimationFrames = append(sprite.AnimationFrames, []float32{column, row})
+		}
+	} else {
+		sprite.UseAtlasRects = true
+		sheetW, sheetH := float32(sheet.Meta.Size.W), float32(sheet.Meta.Size.H)
+		for i, frame := range frames {
+			rect := frame.Frame
+			if rect.W <= 0 || rect.H <= 0 || rect.X < 0 || rect.Y < 0 || rect.X+rect.W > sheet.Meta.Size.W || rect.Y+rect.H > sheet.Meta.Size.H {
+				return Sprite{}, fmt.Errorf("%s: frame %d (%s) does not fit on the %dx%d sheet", path, i, frame.Filename, sheet.Meta.Size.W, sheet.Meta.Size.H)
+			}
+			// (u0, v0, u1, v1), with v counting from the bottom of the sheet, as the texture is flipped for GL
+			sprite.AnimationFrames = append(sprite.AnimationFrames, []float32{
+				float32(rect.X) / sheetW,
+				1 - float32(rect.Y+rect.H)/sheetH,
+				float32(rect.X+rect.W) / sheetW,
+				1 - float32(rect.Y)/sheetH,
+			})
+		}
+	}
+	for _, frame := range frames {
+		sprite.FrameDurations = append(sprite.FrameDurations, frame.Duration)
+	}
+
+	// Validate that the tags point to existing frames
+	for _, tag := range sheet.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return Sprite{}, fmt.Errorf("%s: tag %s references frames %d-%d, but there are only %d frames", path, tag.Name, tag.From, tag.To, len(frames))
+		}
+		sprite.AnimationTags[tag.Name] = [2]int{tag.From, tag.To}
+	}
+
+	return sprite, nil
+}
+
+// Returns the tile size when the sheet is a square grid of square tiles that all frames line up with,
+// so the Sprite can use Divisions.
+func asepriteGridTileSize(sheet asepriteFile, frames []asepriteFrame) (int, bool) {
+	tileSize := frames[0].Frame.W
+	if tileSize <= 0 || sheet.Meta.Size.W != sheet.Meta.Size.H || sheet.Meta.Size.W%tileSize != 0 {
+		return 0, false
+	}
+	for _, frame := range frames {
+		if frame.Frame.W != tileSize || frame.Frame.H != tileSize || frame.Frame.X%tileSize != 0 || frame.Frame.Y%tileSize != 0 {
+			return 0, false
+		}
+	}
+	return tileSize, true
+}
+
+// Aseprite exports frames either as an array, or as an object keyed by filename.
+// For the object variant we keep the order of the keys, as that is the frame order.
+func parseAsepriteFrames(raw json.RawMessage) ([]asepriteFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	// Array format
+	if raw[0] == '[' {
+		var frames []asepriteFrame
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	// Hash format
+	frames := []asepriteFrame{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil { // opening {
+		return nil, err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame asepriteFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.Filename, _ = key.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
@@ -0,0 +1,123 @@
+package gogl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Writes the spritesheet json to a temporary directory and loads it.
+func loadTestAseprite(t *testing.T, source string) (Sprite, string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hero.json")
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sprite, err := LoadSpriteFromJSON(path)
+	return sprite, dir, err
+}
+
+// "Hash" export of a 2x2 grid of 16 pixel tiles. The keys are not in alphabetical order, to check
+// that the frame order of the file is kept.
+const asepriteHashGrid = `{
+	"frames": {
+		"hero 1.aseprite": {"frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "duration": 100},
+		"hero 0.aseprite": {"frame": {"x": 0, "y": 16, "w": 16, "h": 16}, "duration": 200},
+		"hero 2.aseprite": {"frame": {"x": 16, "y": 16, "w": 16, "h": 16}, "duration": 300}
+	},
+	"meta": {
+		"image": "hero.png",
+		"size": {"w": 32, "h": 32},
+		"frameTags": [{"name": "walk", "from": 1, "to": 2}]
+	}
+}`
+
+// "Array" export as a horizontal strip (Aseprite's default layout) of three 16x24 frames.
+const asepriteArrayStrip = `{
+	"frames": [
+		{"filename": "hero 0.aseprite", "frame": {"x": 0, "y": 0, "w": 16, "h": 24}, "duration": 100},
+		{"filename": "hero 1.aseprite", "frame": {"x": 16, "y": 0, "w": 16, "h": 24}, "duration": 100},
+		{"filename": "hero 2.aseprite", "frame": {"x": 32, "y": 0, "w": 16, "h": 24}, "duration": 50}
+	],
+	"meta": {
+		"image": "sheets/hero.png",
+		"size": {"w": 64, "h": 48},
+		"frameTags": []
+	}
+}`
+
+func TestLoadSpriteFromJSONGrid(t *testing.T) {
+	sprite, dir, err := loadTestAseprite(t, asepriteHashGrid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sprite.UseAtlasRects {
+		t.Error("UseAtlasRects is set for a square grid")
+	}
+	if sprite.Divisions != 2 {
+		t.Errorf("Divisions is %d, want 2", sprite.Divisions)
+	}
+	if want := filepath.Join(dir, "hero.png"); sprite.TextureSource != want {
+		t.Errorf("TextureSource is %q, want %q", sprite.TextureSource, want)
+	}
+
+	// {column, row}, rows counted from the bottom of the sheet
+	wantFrames := [][]float32{{1, 1}, {0, 0}, {1, 0}}
+	if !reflect.DeepEqual(sprite.AnimationFrames, wantFrames) {
+		t.Errorf("AnimationFrames is %v, want %v", sprite.AnimationFrames, wantFrames)
+	}
+	if want := []int{100, 200, 300}; !reflect.DeepEqual(sprite.FrameDurations, want) {
+		t.Errorf("FrameDurations is %v, want %v", sprite.FrameDurations, want)
+	}
+	if want := map[string][2]int{"walk": {1, 2}}; !reflect.DeepEqual(sprite.AnimationTags, want) {
+		t.Errorf("AnimationTags is %v, want %v", sprite.AnimationTags, want)
+	}
+}
+
+func TestLoadSpriteFromJSONStrip(t *testing.T) {
+	sprite, dir, err := loadTestAseprite(t, asepriteArrayStrip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sprite.UseAtlasRects {
+		t.Error("UseAtlasRects is not set for a strip of non-square frames")
+	}
+	if want := filepath.Join(dir, "sheets", "hero.png"); sprite.TextureSource != want {
+		t.Errorf("TextureSource is %q, want %q", sprite.TextureSource, want)
+	}
+
+	// {u0, v0, u1, v1}: the strip is the top half of the sheet, so v runs from 0.5 to 1
+	wantFrames := [][]float32{
+		{0, 0.5, 0.25, 1},
+		{0.25, 0.5, 0.5, 1},
+		{0.5, 0.5, 0.75, 1},
+	}
+	if !reflect.DeepEqual(sprite.AnimationFrames, wantFrames) {
+		t.Errorf("AnimationFrames is %v, want %v", sprite.AnimationFrames, wantFrames)
+	}
+	if want := []int{100, 100, 50}; !reflect.DeepEqual(sprite.FrameDurations, want) {
+		t.Errorf("FrameDurations is %v, want %v", sprite.FrameDurations, want)
+	}
+}
+
+func TestLoadSpriteFromJSONErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json": `{"frames": [`,
+		"no frames":    `{"frames": [], "meta": {"image": "a.png", "size": {"w": 16, "h": 16}}}`,
+		"no image":     `{"frames": [{"frame": {"x": 0, "y": 0, "w": 16, "h": 16}}], "meta": {"size": {"w": 16, "h": 16}}}`,
+		"no size":      `{"frames": [{"frame": {"x": 0, "y": 0, "w": 16, "h": 16}}], "meta": {"image": "a.png"}}`,
+		"frame outside the sheet": `{"frames": [{"frame": {"x": 8, "y": 0, "w": 16, "h": 16}}],
+			"meta": {"image": "a.png", "size": {"w": 16, "h": 16}}}`,
+		"tag past the last frame": `{"frames": [{"frame": {"x": 0, "y": 0, "w": 16, "h": 16}}],
+			"meta": {"image": "a.png", "size": {"w": 16, "h": 16}, "frameTags": [{"name": "walk", "from": 0, "to": 1}]}}`,
+	}
+	for name, source := range tests {
+		if _, _, err := loadTestAseprite(t, source); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gogl
+
+/*
+	CAMERA
+
+	Camera2D gives us pan and zoom on top of an orthographic projection.
+	World coordinates are in pixels at Zoom 1, with y pointing up.
+
+	Usage:
+		camera := gogl.Camera2D{Zoom: 1, Viewport: [2]float32{800, 600}}
+		vp := camera.ViewProjection()
+		data.Program.SetMat4("view_projection", &vp)
+*/
+
+type Camera2D struct {
+	Position [2]float32 // World position that ends up in the center of the screen
+	Zoom     float32    // 1 is no zoom, 2 makes everything twice as big. 0 is treated as 1.
+	Viewport [2]float32 // Size of the viewport in pixels (width, height)
+}
+
+// Returns the combined view and projection matrix of the camera, to be uploaded with Program.SetMat4().
+func (camera *Camera2D) ViewProjection() [16]float32 {
+	halfWidth, halfHeight := camera.halfExtents()
+	return Ortho2D(
+		camera.Position[0]-halfWidth, camera.Position[0]+halfWidth,
+		camera.Position[1]-halfHeight, camera.Position[1]+halfHeight,
+	)
+}
+
+// Converts a screen position (pixels, origin top-left, like the cursor position) to world coordinates.
+func (camera *Camera2D) ScreenToWorld(x, y float32) (float32, float32) {
+	zoom := camera.zoom()
+	worldX := camera.Position[0] + (x-camera.Viewport[0]/2)/zoom
+	worldY := camera.Position[1] - (y-camera.Viewport[1]/2)/zoom
+	return worldX, worldY
+}
+
+// Returns half of the visible world area, taking zoom into account.
+func (camera *Camera2D) halfExtents() (float32, float32) {
+	zoom := camera.zoom()
+	return camera.Viewport[0] / (2 * zoom), camera.Viewport[1] / (2 * zoom)
+}
+
+func (camera *Camera2D) zoom() float32 {
+	if camera.Zoom == 0 {
+		return 1
+	}
+	return camera.Zoom
+}
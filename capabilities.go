@@ -0,0 +1,72 @@
+package gogl
+
+import (
+	"sort"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Extensions supported by the current context, filled on first use by HasExtension().
+var extensions map[string]bool
+
+// Returns true if the current GL context supports the named extension, e.g. "GL_EXT_texture_filter_anisotropic".
+// Needs a current context (call after Init()).
+func HasExtension(name string) bool {
+	loadExtensions()
+	return extensions[name]
+}
+
+// Fills the extensions map, if that didn't happen yet.
+func loadExtensions() {
+	if extensions != nil {
+		return
+	}
+	extensions = make(map[string]bool)
+	count := getInteger(gl.NUM_EXTENSIONS)
+	for i := 0; i < count; i++ {
+		extensions[gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))] = true
+	}
+}
+
+// What the current GL context supports, see Capabilities().
+type GLCapabilities struct {
+	Vendor          string   // e.g. "NVIDIA Corporation"
+	Renderer        string   // Name of the GPU (or software renderer)
+	Version         string   // Full version string, e.g. "4.5.0 NVIDIA 535.54.03"
+	MajorVersion    int      // e.g. 4
+	MinorVersion    int      // e.g. 5
+	MaxTextureSize  int      // Largest width/height of a texture in pixels
+	MaxTextureUnits int      // Number of texture units a fragment shader can sample from at once
+	MaxSamples      int      // Largest sample count for NewMultisampleFramebuffer()
+	Extensions      []string // Supported extensions, sorted
+}
+
+// Queries what the current GL context supports, e.g. to pick atlas sizes or quality settings at startup.
+// Needs a current context (call after Init()).
+func Capabilities() GLCapabilities {
+	capabilities := GLCapabilities{
+		Vendor:          gl.GoStr(gl.GetString(gl.VENDOR)),
+		Renderer:        gl.GoStr(gl.GetString(gl.RENDERER)),
+		Version:         GetVersion(),
+		MajorVersion:    getInteger(gl.MAJOR_VERSION),
+		MinorVersion:    getInteger(gl.MINOR_VERSION),
+		MaxTextureSize:  getInteger(gl.MAX_TEXTURE_SIZE),
+		MaxTextureUnits: getInteger(gl.MAX_TEXTURE_IMAGE_UNITS),
+		MaxSamples:      getInteger(gl.MAX_SAMPLES),
+	}
+
+	loadExtensions()
+	for name := range extensions {
+		capabilities.Extensions = append(capabilities.Extensions, name)
+	}
+	sort.Strings(capabilities.Extensions)
+
+	return capabilities
+}
+
+// Returns the value of an integer GL parameter, e.g. gl.MAX_TEXTURE_SIZE.
+func getInteger(name uint32) int {
+	var value int32
+	gl.GetIntegerv(name, &value)
+	return int(value)
+}
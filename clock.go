@@ -0,0 +1,71 @@
+package gogl
+
+import (
+	"time"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+/*
+	CLOCK
+
+	Clock keeps track of the time between frames. Call clock.Tick() once per
+	game loop; it returns the seconds that have passed since the previous Tick,
+	which can be used to make movement independent of the framerate.
+*/
+
+// How much weight a new frame gets in the smoothed FPS value (0..1).
+// Lower values give a steadier, but slower reacting, FPS readout.
+const fpsSmoothing = 0.1
+
+// How long before the end of the frame budget LimitFPS() stops sleeping and starts spinning.
+// time.Sleep can overshoot by a millisecond or more, depending on the OS.
+const fpsSpinMargin = 2 * time.Millisecond
+
+type Clock struct {
+	LastTime  float64 // glfw time (in seconds) of the last Tick
+	DeltaTime float64 // Seconds between the last two Ticks
+	FPS       float64 // Smoothed frames per second
+}
+
+// Creates a Clock that starts counting from now.
+func NewClock() *Clock {
+	return &Clock{LastTime: glfw.GetTime()}
+}
+
+// Returns the seconds passed since the last Tick, and updates the smoothed FPS value.
+func (clock *Clock) Tick() float64 {
+	now := glfw.GetTime()
+	clock.DeltaTime = now - clock.LastTime
+	clock.LastTime = now
+
+	if clock.DeltaTime > 0 {
+		fps := 1 / clock.DeltaTime
+		if clock.FPS == 0 {
+			// First frame, nothing to smooth yet
+			clock.FPS = fps
+		} else {
+			clock.FPS = clock.FPS + fpsSmoothing*(fps-clock.FPS)
+		}
+	}
+
+	return clock.DeltaTime
+}
+
+// Waits until 1/target seconds have passed since the last Tick, so the game loop runs at (at most) target
+// frames per second, also without vsync. Call it at the end of the loop, after swapping the buffers.
+// Most of the wait is slept, the last bit is spent busy-waiting, as sleeping isn't precise enough.
+// Does nothing when target is 0 or less, or when the frame took longer than its budget.
+func (clock *Clock) LimitFPS(target int) {
+	if target <= 0 {
+		return
+	}
+	frameEnd := clock.LastTime + 1/float64(target)
+
+	remaining := time.Duration((frameEnd - glfw.GetTime()) * float64(time.Second))
+	if remaining > fpsSpinMargin {
+		time.Sleep(remaining - fpsSpinMargin)
+	}
+	for glfw.GetTime() < frameEnd {
+	}
+}
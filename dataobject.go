@@ -1,22 +1,34 @@
 package gogl
 
 import (
+	"sort"
+	"unsafe"
+
 	"github.com/go-gl/gl/v4.5-core/gl"
 )
 
 type DataObject struct {
 	VAOID                VAOID                // id of the vertex array object
 	VBOID                BufferID             // id of the vertex buffer object
-	EBOID                BufferID             // element buffer object for quads
-	Type                 int                  // Lets us know in what format the raw vertex data is defined. GOGL_TRIANGLES, GOGL_QUADS
+	EBOID                BufferID             // element buffer object for the indexed types (quads, meshes, indexed triangles)
+	Type                 int                  // Lets us know in what format the raw vertex data is defined. GOGL_TRIANGLES, GOGL_QUADS, GOGL_TRIANGLE_STRIP, GOGL_TRIANGLE_FAN, GOGL_POINTS, GOGL_MESH, GOGL_INDEXED_TRIANGLES
 	Vertices             []float32            // raw vertex data
-	Indices              []uint32             // when giving the data in quad format, this value should indicate which vertices make a triangle together
+	Transform            [16]float32          // Model matrix (column-major) that Enable() uploads as the "model" uniform, e.g. Translate(x, y, 0).Mul(RotateZ(a)). All zeroes (the default) counts as the identity.
+	Layout               []VertexAttrib       // Optional custom vertex layout, replaces the layout of the Type (which still decides how the vertices are drawn). Set before ProcessData().
+	Indices              []uint32             // when giving the data in an indexed format (quads, mesh, indexed triangles), this value should indicate which vertices make a triangle together
+	Indices16            []uint16             // Same as Indices, but 16 bit (for meshes with less than 65536 vertices). Used instead of Indices when set.
 	ProgramName          string               // Used for keeping track of the program, and hotloading the shaders when they change.
 	Program              *Program             // Contains the id of the GL program, and other data to simplify hotloading shaders.
 	VertexShaderSource   string               // Filepath of the .vert shader. Can be relative.
 	FragmentShaderSource string               // Filepath of the .frag shader. Can be relative.
 	Textures             map[string]TextureID // Map used to avoid loading in textures more than once.
 	Sprites              []Sprite             // List of Sprites that belong to this DataObject.
+	InstanceData         []float32            // Per-instance data for DrawInstanced(): x, y offset and r, g, b, a tint (6 values per instance)
+	InstanceVBOID        BufferID             // id of the buffer that holds InstanceData, created on the first DrawInstanced()
+	Stream               *StreamBuffer        // Persistently mapped vertex buffer, replaces the VBO after EnableStreaming() (see streambuffer.go)
+	vertexCapacity       int                  // Number of float32s the VBO currently has room for
+	indexCapacity        int                  // Number of bytes the EBO currently has room for
+	attribProgramID      ProgramID            // The program the attribute locations in the VAO were looked up in
 }
 
 /*
@@ -37,16 +49,34 @@ func (data *DataObject) ProcessData() {
 	// Create VAO, VBO
 	data.VAOID = GenVertexArray()
 	data.VBOID = GenBuffer(gl.ARRAY_BUFFER)
+	gl.BindVertexArray(uint32(data.VAOID))
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(data.VBOID))
 
-	if data.Type == GOGL_QUADS {
-		// Create Element Buffer Object
+	// Upload the vertices
+	BufferDataFloat32(data.Vertices, gl.ARRAY_BUFFER, gl.STATIC_DRAW)
+	data.vertexCapacity = len(data.Vertices)
+
+	if data.usesIndices() {
+		// Create Element Buffer Object. The EBO binding is stored in the VAO, so it
+		// stays bound to it after we unbind below.
 		data.EBOID = GenBuffer(gl.ELEMENT_ARRAY_BUFFER)
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, uint32(data.EBOID))
+		if data.Indices16 != nil {
+			BufferData(data.Indices16, gl.ELEMENT_ARRAY_BUFFER, gl.STATIC_DRAW)
+			data.indexCapacity = 2 * len(data.Indices16)
+		} else {
+			BufferDataUint32(data.Indices, gl.ELEMENT_ARRAY_BUFFER, gl.STATIC_DRAW)
+			data.indexCapacity = 4 * len(data.Indices)
+		}
 	}
 
-	// Unbind
+	// Let the VAO capture the attribute layout
+	data.setupAttributes()
+
+	// Unbind (VAO first, so the EBO unbind doesn't end up in the VAO)
 	gl.BindVertexArray(0)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	if data.Type == GOGL_QUADS {
+	if data.usesIndices() {
 		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
 	}
 }
@@ -56,44 +86,354 @@ After a DataObject has been build through DataObject.ProcessData(),
 you can enable it to start drawing to the screen. It binds all the things that need to be bound
 for the DataObject to be active. If you want to use attached Sprites, activate them separately: `sp := data.SelectSprite(0); sp.SetUniforms()`
 This function can be called as often as you want, to switch between multiple DataObjects.
+The buffers and attribute layout are stored in the VAO, so to change the vertex data afterwards,
+use UpdateVertices() and UpdateIndices(). Transform is uploaded as the "model" uniform (a mat4),
+shaders that don't use it can ignore it.
 */
 func (data *DataObject) Enable() {
 
 	// Use Program
-	UseProgram((*data.Program).ID)
+	data.Program.Use()
+
+	// Upload the model transform
+	transform := data.Transform
+	if transform == ([16]float32{}) {
+		transform = Identity()
+	}
+	data.Program.SetMat4("model", &transform)
+
+	// A hotloaded or switched program may have put its inputs at other locations
+	if data.Program.ID != data.attribProgramID {
+		data.RefreshAttributes()
+	}
 
 	// Bind VAO
 	gl.BindVertexArray(uint32(data.VAOID))
 
-	// Bind VBO
+	// Bind VBO, so UpdateVertices() and raw gl calls act on this DataObject's buffer
 	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(data.VBOID))
-	BufferDataFloat32(data.Vertices, gl.ARRAY_BUFFER, gl.STATIC_DRAW)
+}
 
-	if data.Type == GOGL_QUADS {
-		// Bind EBO
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, uint32(data.EBOID))
-		BufferDataUint32(data.Indices, gl.ELEMENT_ARRAY_BUFFER, gl.STATIC_DRAW)
+/*
+Sets the attribute pointers for the vertex layout of the DataObject (see vertexLayout()). The layout is stored in the VAO,
+so this only has to run once, from ProcessData(). The attribute locations are looked up in the
+DataObject's program, so it runs again (through RefreshAttributes()) when Enable() notices that
+the program changed.
+*/
+func (data *DataObject) setupAttributes() {
+	gl.BindVertexArray(uint32(data.VAOID))
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(data.VBOID))
+
+	// Look up the attribute locations by name, so shaders can lay out their inputs as they please.
+	// Falls back to the location in the layout (e.g. 0 for "position" and 1 for "texcoord").
+	layout := data.vertexLayout()
+	stride := layoutStride(layout)
+	offset := int32(0)
+	for _, attrib := range layout {
+		location := data.attribLocation(attrib.Name, attrib.Location)
+
+		// - attrib.Size floats per vertex, non-normalized data (false)
+		// - stride: every vertex is the sum of the attribute sizes long, and a float32 is 4 bytes long
+		// - the attribute starts after the attributes before it in the layout
+		gl.VertexAttribPointer(location, attrib.Size, gl.FLOAT, false, stride*4, gl.PtrOffset(int(offset)*4))
+		gl.EnableVertexAttribArray(location)
+		offset += attrib.Size
+	}
+	data.attribProgramID = data.Program.ID
+}
+
+// Points the VAO's attributes at the locations of the DataObject's current program. Enable() does this
+// by itself when the program changed (hotloading, Program.SetShaders(), or assigning another Program),
+// so you only need it when drawing with raw gl calls instead.
+func (data *DataObject) RefreshAttributes() {
+	gl.BindVertexArray(uint32(data.VAOID))
+
+	// Clear the old locations first, they may now belong to other inputs
+	for location := 0; location < getInteger(gl.MAX_VERTEX_ATTRIBS); location++ {
+		gl.DisableVertexAttribArray(uint32(location))
+		gl.VertexAttribDivisor(uint32(location), 0)
+	}
+
+	// The instance attributes are set again by the next DrawInstanced()
+	data.setupAttributes()
+	gl.BindVertexArray(0)
+}
+
+// The vertex layouts of the DataObject types, see types.go.
+var typeLayouts = map[int][]VertexAttrib{
+	GOGL_TRIANGLES:         {{"position", 0, 3}},
+	GOGL_QUADS:             {{"position", 0, 2}, {"texcoord", 1, 2}},
+	GOGL_TRIANGLE_STRIP:    {{"position", 0, 2}, {"texcoord", 1, 2}},
+	GOGL_TRIANGLE_FAN:      {{"position", 0, 2}, {"texcoord", 1, 2}},
+	GOGL_POINTS:            {{"position", 0, 2}, {"point_size", 1, 1}, {"color", 2, 4}},
+	GOGL_MESH:              {{"position", 0, 3}, {"normal", 2, 3}, {"texcoord", 1, 2}},
+	GOGL_INDEXED_TRIANGLES: {{"position", 0, 3}},
+}
+
+// Returns the Layout of the DataObject if it has one, otherwise the layout of its Type.
+func (data *DataObject) vertexLayout() []VertexAttrib {
+	if data.Layout != nil {
+		return data.Layout
+	}
+	return typeLayouts[data.Type]
+}
+
+// Returns the number of floats per vertex in the layout.
+func layoutStride(layout []VertexAttrib) int32 {
+	stride := int32(0)
+	for _, attrib := range layout {
+		stride += attrib.Size
+	}
+	return stride
+}
+
+/*
+Unbinds the DataObject's VAO and VBO. The attribute layout set up by ProcessData() is stored in this
+DataObject's VAO, so it doesn't leak into other DataObjects, but without unbinding, raw gl calls
+made after drawing (e.g. setting up a buffer by hand) would still change this DataObject's VAO.
+*/
+func (data *DataObject) Disable() {
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// Draws the DataObject with the primitive mode that matches its Type. Call Enable() first.
+func (data *DataObject) Draw() {
+	if data.usesIndices() {
+		gl.DrawElements(gl.TRIANGLES, data.indexCount(), data.indexType(), nil)
+	} else {
+		gl.DrawArrays(data.primitiveMode(), 0, data.vertexCount())
+	}
+}
+
+/*
+Draws the DataObject with the given float uniforms set to other values, and puts the old values back
+afterwards. E.g. to draw a darkened, offset shadow copy before the regular draw, without touching the Sprites:
+
+	data.DrawWith(map[string]float32{"x": sprite.Xn + 0.01, "y": sprite.Yn - 0.01, "brightness": 0.2})
+	data.Draw()
 
-		// - x,y,z data starts at index 0, and is 3 values long (0,3)
-		// - Each vertex is 5 values long, and a float32 is 4 bytes long, so
-		//   the stride is 5*4
-		gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 4*4, nil)
-		gl.EnableVertexAttribArray(0)
+Call Enable() (and SetUniforms() of the Sprite) first.
+*/
+func (data *DataObject) DrawWith(overrides map[string]float32) {
+	previous := make(map[int32]float32, len(overrides))
+	for name, value := range overrides {
+		location := data.Program.GetUniformLocation(name)
+		if location < 0 {
+			continue
+		}
+		var old float32
+		gl.GetUniformfv(uint32(data.Program.ID), location, &old)
+		previous[location] = old
+		gl.Uniform1f(location, value)
+	}
 
-		// - texcoord is two values long (2), and starts at index 3 (gl.PtrOffset(3*4))
-		// - this is the second attribpointer (1), non-normalized data (false)
-		gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(2*4))
-		gl.EnableVertexAttribArray(1)
+	data.Draw()
 
-	} else if data.Type == GOGL_TRIANGLES {
-		gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
-		gl.EnableVertexAttribArray(0)
+	for location, value := range previous {
+		gl.Uniform1f(location, value)
 	}
 }
 
+/*
+Enables and draws all given DataObjects, ordered by program, so every program is only switched to once.
+Objects with the same program keep their relative order. The slice itself is not reordered.
+Note that uniforms belong to the program, so objects that share a program also share its uniform values
+here; set them before calling this, or draw objects that need different values one by one.
+*/
+func DrawGroup(objects []*DataObject) {
+	sorted := make([]*DataObject, len(objects))
+	copy(sorted, objects)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Program.ID < sorted[j].Program.ID
+	})
+
+	for _, data := range sorted {
+		// Enable() only switches programs when it differs from the current one (see UseProgram())
+		data.Enable()
+		data.Draw()
+	}
+	if len(sorted) > 0 {
+		sorted[len(sorted)-1].Disable()
+	}
+}
+
+// Returns true when the DataObject's Type draws its vertices through Indices (with an EBO).
+func (data *DataObject) usesIndices() bool {
+	return data.Type == GOGL_QUADS || data.Type == GOGL_MESH || data.Type == GOGL_INDEXED_TRIANGLES
+}
+
+// Returns the gl primitive mode to draw the DataObject with.
+func (data *DataObject) primitiveMode() uint32 {
+	switch data.Type {
+	case GOGL_TRIANGLE_STRIP:
+		return gl.TRIANGLE_STRIP
+	case GOGL_TRIANGLE_FAN:
+		return gl.TRIANGLE_FAN
+	case GOGL_POINTS:
+		return gl.POINTS
+	}
+	return gl.TRIANGLES
+}
+
+// Returns the number of indices, from Indices16 if set, otherwise from Indices.
+func (data *DataObject) indexCount() int32 {
+	if data.Indices16 != nil {
+		return int32(len(data.Indices16))
+	}
+	return int32(len(data.Indices))
+}
+
+// Returns the gl type of the indices: gl.UNSIGNED_SHORT for Indices16, gl.UNSIGNED_INT for Indices.
+func (data *DataObject) indexType() uint32 {
+	if data.Indices16 != nil {
+		return gl.UNSIGNED_SHORT
+	}
+	return gl.UNSIGNED_INT
+}
+
+// Returns the number of vertices in Vertices, based on the vertex layout.
+func (data *DataObject) vertexCount() int32 {
+	stride := layoutStride(data.vertexLayout())
+	if stride == 0 {
+		return 0
+	}
+	return int32(len(data.Vertices)) / stride
+}
+
+// Returns the location of the named attribute in the DataObject's program,
+// or the fallback location when the shader doesn't declare (or use) it.
+func (data *DataObject) attribLocation(name string, fallback uint32) uint32 {
+	location := data.Program.GetAttribLocation(name)
+	if location < 0 {
+		return fallback
+	}
+	return uint32(location)
+}
+
 // Calls Update on all the Sprites in the Sprite list.
 func (data *DataObject) Update() {
 	for i := range data.Sprites {
 		data.Sprites[i].Update()
 	}
 }
+
+// Replaces the vertex data, and streams it into the existing VBO with gl.BufferSubData.
+// The VBO is only reallocated when the new data doesn't fit in it.
+// After EnableStreaming(), write into StreamVertices() instead.
+func (data *DataObject) UpdateVertices(newVertices []float32) {
+	data.Vertices = newVertices
+	if len(newVertices) == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(data.VBOID))
+	if len(newVertices) > data.vertexCapacity {
+		BufferDataFloat32(newVertices, gl.ARRAY_BUFFER, gl.DYNAMIC_DRAW)
+		data.vertexCapacity = len(newVertices)
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, 4*len(newVertices), gl.Ptr(newVertices))
+	}
+}
+
+// Replaces the index data, and streams it into the existing EBO with gl.BufferSubData.
+// The EBO is only reallocated when the new data doesn't fit in it.
+// Only applies to DataObjects that use an EBO (GOGL_QUADS, GOGL_MESH, GOGL_INDEXED_TRIANGLES).
+func (data *DataObject) UpdateIndices(newIndices []uint32) {
+	data.Indices = newIndices
+	data.Indices16 = nil
+	if len(newIndices) == 0 {
+		return
+	}
+	streamIndices(data, newIndices)
+}
+
+// Same as UpdateIndices(), but for 16 bit indices (see Indices16).
+func (data *DataObject) UpdateIndices16(newIndices []uint16) {
+	data.Indices16 = newIndices
+	if len(newIndices) == 0 {
+		return
+	}
+	streamIndices(data, newIndices)
+}
+
+// Streams the indices into the EBO of the DataObject, reallocating it when they don't fit.
+func streamIndices[T uint16 | uint32](data *DataObject, newIndices []T) {
+	var zero T
+	byteSize := int(unsafe.Sizeof(zero)) * len(newIndices)
+
+	// The EBO binding is part of the VAO state, so bind the VAO first
+	gl.BindVertexArray(uint32(data.VAOID))
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, uint32(data.EBOID))
+	if byteSize > data.indexCapacity {
+		BufferData(newIndices, gl.ELEMENT_ARRAY_BUFFER, gl.DYNAMIC_DRAW)
+		data.indexCapacity = byteSize
+	} else {
+		gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, 0, byteSize, gl.Ptr(newIndices))
+	}
+}
+
+// Number of float32s per instance in InstanceData
+const instanceStride = 6
+
+/*
+Draws the DataObject count times in one draw call. InstanceData should hold 6 values per instance:
+an x, y offset (attribute "instance_offset", location 4 if the shader doesn't name it) followed by an
+r, g, b, a tint (attribute "instance_tint", location 5). Call Enable() first.
+*/
+func (data *DataObject) DrawInstanced(count int) {
+	if count <= 0 || len(data.InstanceData) < count*instanceStride {
+		return
+	}
+
+	gl.BindVertexArray(uint32(data.VAOID))
+
+	// Upload the instance data to its own buffer
+	if data.InstanceVBOID == 0 {
+		data.InstanceVBOID = GenBuffer(gl.ARRAY_BUFFER)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(data.InstanceVBOID))
+	BufferDataFloat32(data.InstanceData, gl.ARRAY_BUFFER, gl.DYNAMIC_DRAW)
+
+	// Attribute divisor 1 means: advance once per instance instead of once per vertex
+	offsetLocation := data.attribLocation("instance_offset", instanceOffsetLocation)
+	gl.VertexAttribPointer(offsetLocation, 2, gl.FLOAT, false, instanceStride*4, nil)
+	gl.EnableVertexAttribArray(offsetLocation)
+	gl.VertexAttribDivisor(offsetLocation, 1)
+
+	tintLocation := data.attribLocation("instance_tint", instanceTintLocation)
+	gl.VertexAttribPointer(tintLocation, 4, gl.FLOAT, false, instanceStride*4, gl.PtrOffset(2*4))
+	gl.EnableVertexAttribArray(tintLocation)
+	gl.VertexAttribDivisor(tintLocation, 1)
+
+	// Draw
+	if data.usesIndices() {
+		gl.DrawElementsInstanced(gl.TRIANGLES, data.indexCount(), data.indexType(), nil, int32(count))
+	} else {
+		gl.DrawArraysInstanced(data.primitiveMode(), 0, data.vertexCount(), int32(count))
+	}
+
+	// Rebind the regular VBO, so Enable()/UpdateVertices() keep working on the right buffer
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(data.VBOID))
+}
+
+// Returns a processed DataObject with a quad that covers the whole screen (-1..1), with texture
+// coordinates running from 0..1. Handy for post-processing shaders, e.g. on a Framebuffer texture.
+func NewFullscreenQuad(programName, vertexShaderSource, fragmentShaderSource string) *DataObject {
+	data := &DataObject{
+		Type: GOGL_QUADS,
+		Vertices: []float32{
+			// x, y, u, v
+			-1, -1, 0, 0,
+			1, -1, 1, 0,
+			1, 1, 1, 1,
+			-1, 1, 0, 1,
+		},
+		Indices:              []uint32{0, 1, 2, 0, 2, 3},
+		ProgramName:          programName,
+		VertexShaderSource:   vertexShaderSource,
+		FragmentShaderSource: fragmentShaderSource,
+	}
+	data.ProcessData()
+	return data
+}
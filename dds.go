@@ -0,0 +1,178 @@
+package gogl
+
+/*
+	DDS
+
+	Loads block compressed (S3TC/BCn) textures from .dds files, and uploads them
+	as they are: the GPU samples the compressed blocks directly. BC1 (DXT1) takes
+	4 bits per pixel and BC3 (DXT5) 8, against 32 for RGBA, so large atlases take
+	4 to 8 times less memory. DXT3 is supported as well. Other DDS formats (DX10
+	header, uncompressed, cubemaps, volume textures) are not.
+
+	S3TC is an extension, not part of core GL, but practically every desktop GPU
+	has it. LoadDDSToTexture() returns an error when it is missing.
+
+	DDS stores its rows top to bottom, and compressed blocks can't be flipped
+	like LoadImageToTexture() does with PNG rows. So the texture ends up upside
+	down compared to PNG textures: flip the image when exporting the .dds, or
+	use 1-v as texture coordinate in the shader.
+
+	Usage:
+		texId, err := gogl.LoadDDSToTexture("assets/tiles.dds")
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// From the S3TC extension (GL_EXT_texture_compression_s3tc), which is not part of the 4.5 core profile.
+const (
+	glCOMPRESSED_RGBA_S3TC_DXT1 = 0x83F1
+	glCOMPRESSED_RGBA_S3TC_DXT3 = 0x83F2
+	glCOMPRESSED_RGBA_S3TC_DXT5 = 0x83F3
+)
+
+// Byte offsets in a .dds file: the "DDS " magic, followed by the 124 byte header, followed by the data.
+const (
+	ddsFlagsOffset    = 8
+	ddsHeightOffset   = 12
+	ddsWidthOffset    = 16
+	ddsMipCountOffset = 28
+	ddsFourCCOffset   = 84
+	ddsDataOffset     = 128
+)
+
+// Header flag that says the mipmap count field is filled in (DDSD_MIPMAPCOUNT).
+const ddsFlagMipMapCount = 0x20000
+
+// Loads the .dds file into a texture, with all mipmap levels that are in the file.
+// Wrap and filter settings are the same as for LoadImageToTexture().
+func LoadDDSToTexture(path string) (TextureID, error) {
+	if !HasExtension("GL_EXT_texture_compression_s3tc") {
+		return 0, fmt.Errorf("%s: can't load DDS texture, the GPU doesn't support S3TC texture compression (GL_EXT_texture_compression_s3tc)", path)
+	}
+
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	dds, err := parseDDS(fileData, getInteger(gl.MAX_TEXTURE_SIZE))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	texId := GenTexture()
+	BindTexture(texId)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+
+	// Compressed textures can't be mipmapped by GL, so only use the levels from the file
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, int32(len(dds.Levels)-1))
+
+	levelWidth, levelHeight := dds.Width, dds.Height
+	for level, blocks := range dds.Levels {
+		// target, level, internalformat, width, height, border, imageSize, *data
+		gl.CompressedTexImage2D(gl.TEXTURE_2D, int32(level), dds.Format, int32(levelWidth), int32(levelHeight), 0, int32(len(blocks)), gl.Ptr(blocks))
+		levelWidth, levelHeight = nextMipSize(levelWidth), nextMipSize(levelHeight)
+	}
+
+	textureSizes[texId] = [2]int{dds.Width, dds.Height}
+	textureFormats[texId] = dds.Format
+
+	return texId, nil
+}
+
+// The contents of a .dds file, ready for uploading.
+type ddsImage struct {
+	Width  int
+	Height int
+	Format uint32   // One of the glCOMPRESSED_RGBA_S3TC_* formats
+	Levels [][]byte // Compressed blocks per mipmap level, starting with the full size
+}
+
+// Parses the header of a .dds file, and slices the mipmap levels out of the data after it.
+// Textures bigger than maxSize (in either direction) are rejected.
+func parseDDS(fileData []byte, maxSize int) (ddsImage, error) {
+	if len(fileData) < ddsDataOffset || string(fileData[0:4]) != "DDS " {
+		return ddsImage{}, fmt.Errorf("not a DDS file")
+	}
+
+	dds := ddsImage{
+		Width:  int(binary.LittleEndian.Uint32(fileData[ddsWidthOffset:])),
+		Height: int(binary.LittleEndian.Uint32(fileData[ddsHeightOffset:])),
+	}
+	if dds.Width == 0 || dds.Height == 0 {
+		return ddsImage{}, fmt.Errorf("invalid texture size %dx%d", dds.Width, dds.Height)
+	}
+	if dds.Width > maxSize || dds.Height > maxSize {
+		return ddsImage{}, fmt.Errorf("texture size %dx%d is larger than the GPU supports (%d)", dds.Width, dds.Height, maxSize)
+	}
+
+	// The mipmap count is only valid when its flag is set, and can't be more than the full chain down to 1x1
+	mipCount := 1
+	if binary.LittleEndian.Uint32(fileData[ddsFlagsOffset:])&ddsFlagMipMapCount != 0 {
+		mipCount = int(binary.LittleEndian.Uint32(fileData[ddsMipCountOffset:]))
+		if fullChain := mipChainLength(dds.Width, dds.Height); mipCount > fullChain {
+			mipCount = fullChain
+		}
+		if mipCount < 1 {
+			mipCount = 1
+		}
+	}
+
+	blockSize := 16 // bytes per 4x4 block
+	switch fourCC := string(fileData[ddsFourCCOffset : ddsFourCCOffset+4]); fourCC {
+	case "DXT1":
+		dds.Format = glCOMPRESSED_RGBA_S3TC_DXT1
+		blockSize = 8
+	case "DXT3":
+		dds.Format = glCOMPRESSED_RGBA_S3TC_DXT3
+	case "DXT5":
+		dds.Format = glCOMPRESSED_RGBA_S3TC_DXT5
+	default:
+		return ddsImage{}, fmt.Errorf("unsupported DDS format %q, only DXT1, DXT3 and DXT5 are supported", fourCC)
+	}
+
+	// Check that all levels are there, so we don't end up with half a texture
+	offset := ddsDataOffset
+	levelWidth, levelHeight := dds.Width, dds.Height
+	for level := 0; level < mipCount; level++ {
+		size := ((levelWidth + 3) / 4) * ((levelHeight + 3) / 4) * blockSize
+		if offset+size > len(fileData) {
+			return ddsImage{}, fmt.Errorf("file ends in mipmap level %d", level)
+		}
+		dds.Levels = append(dds.Levels, fileData[offset:offset+size])
+		offset += size
+		levelWidth, levelHeight = nextMipSize(levelWidth), nextMipSize(levelHeight)
+	}
+
+	return dds, nil
+}
+
+// Returns the width or height of the next mipmap level: half the size, but at least 1.
+func nextMipSize(size int) int {
+	if size > 1 {
+		return size / 2
+	}
+	return 1
+}
+
+// Returns the number of mipmap levels from the full size down to 1x1: floor(log2(max(width, height))) + 1.
+func mipChainLength(width, height int) int {
+	size := width
+	if height > size {
+		size = height
+	}
+	levels := 1
+	for size > 1 {
+		size /= 2
+		levels++
+	}
+	return levels
+}
@@ -0,0 +1,139 @@
+package gogl
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// Builds a .dds file: the header, followed by dataLength bytes where every byte is its mipmap level
+// (when levelSizes is given), so tests can check where the levels were sliced.
+func ddsTestFile(width, height, flags, mipCount uint32, fourCC string, levelSizes []int, dataLength int) []byte {
+	fileData := make([]byte, ddsDataOffset, ddsDataOffset+dataLength)
+	copy(fileData, "DDS ")
+	binary.LittleEndian.PutUint32(fileData[4:], 124) // header size
+	binary.LittleEndian.PutUint32(fileData[ddsFlagsOffset:], flags)
+	binary.LittleEndian.PutUint32(fileData[ddsHeightOffset:], height)
+	binary.LittleEndian.PutUint32(fileData[ddsWidthOffset:], width)
+	binary.LittleEndian.PutUint32(fileData[ddsMipCountOffset:], mipCount)
+	copy(fileData[ddsFourCCOffset:], fourCC)
+
+	for level, size := range levelSizes {
+		for i := 0; i < size; i++ {
+			fileData = append(fileData, byte(level))
+		}
+	}
+	for len(fileData) < ddsDataOffset+dataLength {
+		fileData = append(fileData, 0xFF)
+	}
+	return fileData
+}
+
+func TestParseDDS(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileData       []byte
+		wantFormat     uint32
+		wantLevelSizes []int
+	}{
+		{
+			name:           "DXT1 without mipmaps",
+			fileData:       ddsTestFile(8, 8, 0, 0, "DXT1", []int{32}, 32),
+			wantFormat:     glCOMPRESSED_RGBA_S3TC_DXT1,
+			wantLevelSizes: []int{32},
+		},
+		{
+			name:           "DXT3, size not a multiple of 4",
+			fileData:       ddsTestFile(5, 3, 0, 0, "DXT3", []int{32}, 32),
+			wantFormat:     glCOMPRESSED_RGBA_S3TC_DXT3,
+			wantLevelSizes: []int{32},
+		},
+		{
+			name:           "DXT5 with the full mip chain",
+			fileData:       ddsTestFile(8, 4, ddsFlagMipMapCount, 4, "DXT5", []int{32, 16, 16, 16}, 80),
+			wantFormat:     glCOMPRESSED_RGBA_S3TC_DXT5,
+			wantLevelSizes: []int{32, 16, 16, 16},
+		},
+		{
+			name:           "mip count without its flag is ignored",
+			fileData:       ddsTestFile(8, 4, 0, 4, "DXT5", []int{32}, 80),
+			wantFormat:     glCOMPRESSED_RGBA_S3TC_DXT5,
+			wantLevelSizes: []int{32},
+		},
+		{
+			name:           "garbage mip count is clamped to the full chain",
+			fileData:       ddsTestFile(8, 4, ddsFlagMipMapCount, 0xFFFFFFFF, "DXT5", []int{32, 16, 16, 16}, 80),
+			wantFormat:     glCOMPRESSED_RGBA_S3TC_DXT5,
+			wantLevelSizes: []int{32, 16, 16, 16},
+		},
+		{
+			name:           "mip count 0 with its flag counts as 1",
+			fileData:       ddsTestFile(4, 4, ddsFlagMipMapCount, 0, "DXT1", []int{8}, 8),
+			wantFormat:     glCOMPRESSED_RGBA_S3TC_DXT1,
+			wantLevelSizes: []int{8},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dds, err := parseDDS(test.fileData, 16384)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dds.Format != test.wantFormat {
+				t.Errorf("Format is %#x, want %#x", dds.Format, test.wantFormat)
+			}
+			levelSizes := []int{}
+			for level, blocks := range dds.Levels {
+				levelSizes = append(levelSizes, len(blocks))
+				for _, b := range blocks {
+					if b != byte(level) {
+						t.Fatalf("level %d holds data of level %d", level, b)
+					}
+				}
+			}
+			if !reflect.DeepEqual(levelSizes, test.wantLevelSizes) {
+				t.Errorf("level sizes are %v, want %v", levelSizes, test.wantLevelSizes)
+			}
+		})
+	}
+}
+
+func TestParseDDSErrors(t *testing.T) {
+	wrongMagic := ddsTestFile(8, 8, 0, 0, "DXT1", nil, 32)
+	copy(wrongMagic, "PNG ")
+
+	tests := map[string][]byte{
+		"empty file":               {},
+		"truncated header":         ddsTestFile(8, 8, 0, 0, "DXT1", nil, 32)[:ddsDataOffset-1],
+		"wrong magic":              wrongMagic,
+		"zero width":               ddsTestFile(0, 8, 0, 0, "DXT1", nil, 32),
+		"zero height":              ddsTestFile(8, 0, 0, 0, "DXT1", nil, 32),
+		"larger than the GPU":      ddsTestFile(32768, 4, 0, 0, "DXT1", nil, 32768/4*8),
+		"unsupported FourCC":       ddsTestFile(8, 8, 0, 0, "DX10", nil, 32),
+		"uncompressed":             ddsTestFile(8, 8, 0, 0, "\x00\x00\x00\x00", nil, 256),
+		"truncated data":           ddsTestFile(8, 8, 0, 0, "DXT1", nil, 31),
+		"truncated in a mip level": ddsTestFile(8, 8, ddsFlagMipMapCount, 4, "DXT5", nil, 64+16+15),
+	}
+	for name, fileData := range tests {
+		if _, err := parseDDS(fileData, 16384); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
+
+func TestMipChainLength(t *testing.T) {
+	tests := map[[2]int]int{
+		{1, 1}:     1,
+		{2, 1}:     2,
+		{8, 4}:     4,
+		{5, 3}:     3,
+		{1, 1024}:  11,
+		{1000, 10}: 10,
+	}
+	for size, want := range tests {
+		if got := mipChainLength(size[0], size[1]); got != want {
+			t.Errorf("mipChainLength(%d, %d) is %d, want %d", size[0], size[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package gogl
+
+/*
+	FONT
+
+	Draws text using a bitmap font: a texture atlas with all the characters on it,
+	plus a JSON file that describes where each character is. The format is:
+
+	{
+		"image": "font.png",      // path to the atlas, relative to the json file
+		"lineHeight": 16,         // distance between two lines, in pixels
+		"glyphs": {
+			"A": {"x": 0, "y": 0, "w": 8, "h": 12, "xoffset": 0, "yoffset": 2, "xadvance": 9},
+			...
+		}
+	}
+
+	x, y, w, h is the rectangle of the character on the atlas in pixels (origin top-left).
+	xoffset and yoffset move the character relative to the cursor, xadvance moves the cursor
+	to the next character. These are the same fields as in the AngelCode .fnt format, so
+	converting one to the other is straightforward.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+type Glyph struct {
+	X        int `json:"x"`
+	Y        int `json:"y"`
+	Width    int `json:"w"`
+	Height   int `json:"h"`
+	XOffset  int `json:"xoffset"`
+	YOffset  int `json:"yoffset"`
+	XAdvance int `json:"xadvance"`
+}
+
+type Font struct {
+	Texture       TextureID      // The atlas with all the characters
+	TextureWidth  int            // Width of the atlas in pixels
+	TextureHeight int            // Height of the atlas in pixels
+	LineHeight    int            // Distance between two lines in pixels
+	Glyphs        map[rune]Glyph // Metrics per character
+	batch         *DataObject    // Quad batch that DrawText() fills, created on first use
+}
+
+type fontFile struct {
+	Image      string           `json:"image"`
+	LineHeight int              `json:"lineHeight"`
+	Glyphs     map[string]Glyph `json:"glyphs"`
+}
+
+// Loads a bitmap font from its JSON description, and the atlas texture it points to.
+func LoadFont(path string) (*Font, error) {
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition fontFile
+	if err := json.Unmarshal(fileData, &definition); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	font := &Font{
+		LineHeight: definition.LineHeight,
+		Glyphs:     make(map[rune]Glyph),
+	}
+	for character, glyph := range definition.Glyphs {
+		runes := []rune(character)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("%s: glyph key %q should be a single character", path, character)
+		}
+		font.Glyphs[runes[0]] = glyph
+	}
+
+	// Load the atlas (image path is relative to the json). A grayscale atlas becomes a single-channel
+	// texture, sample .r instead of .a in the shader for that one.
+	pixels, dimensions, format, err := loadTexturePixels(filepath.Join(filepath.Dir(path), definition.Image), true)
+	if err != nil {
+		return nil, err
+	}
+	font.Texture = uploadTexture(pixels, dimensions, format, DefaultTextureOptions())
+	font.TextureWidth = dimensions[0]
+	font.TextureHeight = dimensions[1]
+
+	return font, nil
+}
+
+/*
+Draws the text with the given program. x, y is the top-left of the first line, and
+scale is applied to the pixel sizes of the glyphs, so positions end up in the same
+units as x, y (e.g. pixels when the program uses an Ortho2D projection). y points up.
+
+The program receives the quads in the GOGL_QUADS layout ("position" and "texcoord"),
+with the atlas bound to texture unit 0 (sampler uniform "tex").
+*/
+func (font *Font) DrawText(program *Program, text string, x, y, scale float32) {
+	vertices := []float32{}
+	indices := []uint32{}
+
+	cursorX, cursorY := x, y
+	for _, character := range text {
+		if character == '\n' {
+			cursorX = x
+			cursorY -= float32(font.LineHeight) * scale
+			continue
+		}
+		glyph, ok := font.Glyphs[character]
+		if !ok {
+			continue
+		}
+
+		// Quad corners on the screen
+		left := cursorX + float32(glyph.XOffset)*scale
+		right := left + float32(glyph.Width)*scale
+		top := cursorY - float32(glyph.YOffset)*scale
+		bottom := top - float32(glyph.Height)*scale
+
+		// Quad corners on the atlas (the texture is flipped, so v runs bottom to top)
+		u0 := float32(glyph.X) / float32(font.TextureWidth)
+		u1 := float32(glyph.X+glyph.Width) / float32(font.TextureWidth)
+		v0 := 1 - float32(glyph.Y+glyph.Height)/float32(font.TextureHeight)
+		v1 := 1 - float32(glyph.Y)/float32(font.TextureHeight)
+
+		first := uint32(len(vertices) / 4)
+		vertices = append(vertices,
+			left, top, u0, v1,
+			right, top, u1, v1,
+			right, bottom, u1, v0,
+			left, bottom, u0, v0,
+		)
+		indices = append(indices, first, first+1, first+2, first, first+2, first+3)
+
+		cursorX += float32(glyph.XAdvance) * scale
+	}
+
+	if len(indices) == 0 {
+		return
+	}
+
+	// Create the quad batch on first use
+	if font.batch == nil {
+		font.batch = &DataObject{Type: GOGL_QUADS}
+		font.batch.VAOID = GenVertexArray()
+		font.batch.VBOID = GenBuffer(gl.ARRAY_BUFFER)
+		font.batch.EBOID = GenBuffer(gl.ELEMENT_ARRAY_BUFFER)
+	}
+
+	// The attribute locations come from the program, so set up the layout again when it changes
+	if font.batch.Program != program {
+		font.batch.Program = program
+		font.batch.setupAttributes()
+	}
+
+	// Stream the quads into the batch
+	font.batch.Enable()
+	font.batch.UpdateVertices(vertices)
+	font.batch.UpdateIndices(indices)
+	program.SetTexture("tex", 0, font.Texture)
+	font.batch.Draw()
+}
@@ -0,0 +1,200 @@
+package gogl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+/*
+	FRAMEBUFFER
+
+	A Framebuffer lets us render into a texture instead of onto the screen.
+	That texture can then be used like any other texture (e.g. on a Sprite),
+	which is what we need for post-processing effects.
+
+	Usage:
+		fb, err := gogl.NewFramebuffer(800, 600, true)
+		fb.Bind()
+		// draw scene
+		fb.Unbind()
+		// draw fb.Texture() to the screen with a post-processing shader
+
+	For anti-aliasing, render into a multisampled framebuffer instead, and resolve
+	it into a regular one before sampling from it:
+		msaa, err := gogl.NewMultisampleFramebuffer(800, 600, 4, true)
+		msaa.Bind()
+		// draw scene
+		msaa.Unbind()
+		msaa.ResolveTo(fb)
+		// draw fb.Texture()
+*/
+
+type FramebufferID uint32
+type RenderbufferID uint32
+
+type Framebuffer struct {
+	ID           FramebufferID  // id of the framebuffer object
+	ColorTexture TextureID      // Texture that the color output is rendered into (0 for multisampled framebuffers)
+	ColorBuffer  RenderbufferID // Multisampled color attachment, 0 for regular framebuffers
+	DepthBuffer  RenderbufferID // Depth (+stencil) attachment, 0 if none was requested
+	Width        int
+	Height       int
+	Samples      int // Number of samples per pixel, 0 for regular framebuffers
+}
+
+// Creates a framebuffer with a color texture of the given size.
+// When withDepth is true, a depth/stencil renderbuffer is attached as well, so depth testing works
+// while rendering into it.
+func NewFramebuffer(width, height int, withDepth bool) (*Framebuffer, error) {
+	fb := &Framebuffer{Width: width, Height: height}
+
+	var fbID uint32
+	gl.GenFramebuffers(1, &fbID)
+	fb.ID = FramebufferID(fbID)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbID)
+
+	// Color attachment
+	fb.ColorTexture = GenTexture()
+	BindTexture(fb.ColorTexture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, uint32(fb.ColorTexture), 0)
+	textureSizes[fb.ColorTexture] = [2]int{width, height}
+	textureFormats[fb.ColorTexture] = gl.RGBA
+
+	// Depth attachment
+	if withDepth {
+		fb.DepthBuffer = attachRenderbuffer(gl.DEPTH_STENCIL_ATTACHMENT, gl.DEPTH24_STENCIL8, width, height, 0)
+	}
+
+	if err := fb.checkComplete(); err != nil {
+		return nil, err
+	}
+	return fb, nil
+}
+
+// Creates a multisampled framebuffer, for anti-aliased rendering. samples is the number of samples
+// per pixel (e.g. 4). A multisampled framebuffer can't be sampled from directly, so it has no
+// ColorTexture: use ResolveTo() to copy it into a regular framebuffer first.
+func NewMultisampleFramebuffer(width, height, samples int, withDepth bool) (*Framebuffer, error) {
+	fb := &Framebuffer{Width: width, Height: height, Samples: samples}
+
+	var fbID uint32
+	gl.GenFramebuffers(1, &fbID)
+	fb.ID = FramebufferID(fbID)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbID)
+
+	fb.ColorBuffer = attachRenderbuffer(gl.COLOR_ATTACHMENT0, gl.RGBA8, width, height, samples)
+	if withDepth {
+		fb.DepthBuffer = attachRenderbuffer(gl.DEPTH_STENCIL_ATTACHMENT, gl.DEPTH24_STENCIL8, width, height, samples)
+	}
+
+	if err := fb.checkComplete(); err != nil {
+		return nil, err
+	}
+	return fb, nil
+}
+
+// Creates a renderbuffer and attaches it to the bound framebuffer. samples 0 means not multisampled.
+func attachRenderbuffer(attachment uint32, internalFormat uint32, width, height, samples int) RenderbufferID {
+	var rbID uint32
+	gl.GenRenderbuffers(1, &rbID)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, rbID)
+	if samples > 0 {
+		gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, int32(samples), internalFormat, int32(width), int32(height))
+	} else {
+		gl.RenderbufferStorage(gl.RENDERBUFFER, internalFormat, int32(width), int32(height))
+	}
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, attachment, gl.RENDERBUFFER, rbID)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+	return RenderbufferID(rbID)
+}
+
+// Checks if the bound framebuffer can actually be used, and unbinds it. Deletes it when it can't.
+func (fb *Framebuffer) checkComplete() error {
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		fb.Delete()
+		return fmt.Errorf("framebuffer is incomplete, status: 0x%x", status)
+	}
+	return nil
+}
+
+// Start rendering into this framebuffer instead of the screen.
+// Note that the viewport is not changed, set it to the framebuffer size if it differs from the window.
+func (fb *Framebuffer) Bind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(fb.ID))
+}
+
+// Go back to rendering onto the screen (the default framebuffer).
+func (fb *Framebuffer) Unbind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// Copies the color output into dst, resolving the samples of a multisampled framebuffer into single pixels.
+// Both framebuffers need to be the same size. Leaves the default framebuffer bound.
+func (fb *Framebuffer) ResolveTo(dst *Framebuffer) error {
+	if fb.Width != dst.Width || fb.Height != dst.Height {
+		return fmt.Errorf("can't resolve a %dx%d framebuffer into a %dx%d one", fb.Width, fb.Height, dst.Width, dst.Height)
+	}
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(fb.ID))
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(dst.ID))
+	gl.BlitFramebuffer(0, 0, int32(fb.Width), int32(fb.Height), 0, 0, int32(dst.Width), int32(dst.Height), gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	return nil
+}
+
+// Returns the texture the framebuffer renders into, usable as the Texture of a Sprite.
+func (fb *Framebuffer) Texture() TextureID {
+	return fb.ColorTexture
+}
+
+// Returns a Sprite that shows the whole color texture of the framebuffer (one frame, Divisions 1),
+// e.g. to draw the offscreen rendered scene through a post-processing shader:
+//
+//	data.AddSprite(fb.AsSprite())
+//
+// Not for multisampled framebuffers, use the framebuffer they are resolved into (see ResolveTo()).
+func (fb *Framebuffer) AsSprite() Sprite {
+	return Sprite{
+		Name:            "framebuffer",
+		Divisions:       1,
+		Texture:         fb.ColorTexture,
+		AnimationFrames: [][]float32{{0, 0}},
+		AnimationSpeed:  1,
+		Scale:           1,
+		AnchorX:         0.5,
+		AnchorY:         0.5,
+	}
+}
+
+// Removes the framebuffer and its attachments from GL.
+func (fb *Framebuffer) Delete() {
+	if fb.ColorBuffer != 0 {
+		rbID := uint32(fb.ColorBuffer)
+		gl.DeleteRenderbuffers(1, &rbID)
+		fb.ColorBuffer = 0
+	}
+	if fb.DepthBuffer != 0 {
+		rbID := uint32(fb.DepthBuffer)
+		gl.DeleteRenderbuffers(1, &rbID)
+		fb.DepthBuffer = 0
+	}
+	if fb.ColorTexture != 0 {
+		texID := uint32(fb.ColorTexture)
+		gl.DeleteTextures(1, &texID)
+		delete(textureSizes, fb.ColorTexture)
+		delete(textureFormats, fb.ColorTexture)
+		fb.ColorTexture = 0
+	}
+	fbID := uint32(fb.ID)
+	gl.DeleteFramebuffers(1, &fbID)
+	fb.ID = 0
+}
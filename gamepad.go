@@ -0,0 +1,165 @@
+package gogl
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+/*
+	GAMEPAD
+
+	Gamepad keeps track of the buttons and axes of a controller from frame to
+	frame, like Input does for the keyboard and mouse. Input.PollInput() updates
+	all of them, get one with input.Gamepad(0).
+
+	GLFW 3.2 only has the raw joystick API: buttons and axes are numbered, and
+	the numbering differs per controller and per platform. GamepadMapping turns
+	those numbers into GamepadA, GamepadLeftX, etc. DefaultGamepadMapping is the
+	Xbox (XInput) layout on Windows; set Gamepad.Mapping for other controllers.
+*/
+
+type GamepadButton int
+
+const (
+	GamepadA GamepadButton = iota
+	GamepadB
+	GamepadX
+	GamepadY
+	GamepadLeftBumper
+	GamepadRightBumper
+	GamepadBack
+	GamepadStart
+	GamepadGuide
+	GamepadLeftThumb
+	GamepadRightThumb
+	GamepadDpadUp
+	GamepadDpadRight
+	GamepadDpadDown
+	GamepadDpadLeft
+	gamepadButtonCount
+)
+
+type GamepadAxis int
+
+const (
+	GamepadLeftX        GamepadAxis = iota // -1 (left) .. 1 (right)
+	GamepadLeftY                           // -1 .. 1, direction as reported by the controller
+	GamepadRightX                          // -1 (left) .. 1 (right)
+	GamepadRightY                          // -1 .. 1, direction as reported by the controller
+	GamepadLeftTrigger                     // 0 (released) .. 1 (fully pressed)
+	GamepadRightTrigger                    // 0 (released) .. 1 (fully pressed)
+	gamepadAxisCount
+)
+
+// Which raw joystick button/axis index each GamepadButton/GamepadAxis is, -1 when the controller doesn't have it.
+type GamepadMapping struct {
+	Buttons [gamepadButtonCount]int
+	Axes    [gamepadAxisCount]int
+}
+
+// The layout of an Xbox controller through XInput on Windows, as reported by GLFW 3.2.
+var DefaultGamepadMapping = GamepadMapping{
+	Buttons: [gamepadButtonCount]int{
+		GamepadA: 0, GamepadB: 1, GamepadX: 2, GamepadY: 3,
+		GamepadLeftBumper: 4, GamepadRightBumper: 5,
+		GamepadBack: 6, GamepadStart: 7, GamepadGuide: -1,
+		GamepadLeftThumb: 8, GamepadRightThumb: 9,
+		GamepadDpadUp: 10, GamepadDpadRight: 11, GamepadDpadDown: 12, GamepadDpadLeft: 13,
+	},
+	Axes: [gamepadAxisCount]int{
+		GamepadLeftX: 0, GamepadLeftY: 1,
+		GamepadRightX: 2, GamepadRightY: 3,
+		GamepadLeftTrigger: 4, GamepadRightTrigger: 5,
+	},
+}
+
+// Stick values closer to 0 than this are reported as 0, so a worn stick doesn't drift.
+const defaultGamepadDeadzone = 0.15
+
+type Gamepad struct {
+	Joystick    glfw.Joystick             // Which glfw joystick this is
+	Connected   bool                      // Whether the controller is plugged in
+	Name        string                    // Name the driver gives the controller
+	Mapping     GamepadMapping            // How the raw buttons and axes map to the Gamepad ones
+	Deadzone    float32                   // See defaultGamepadDeadzone
+	buttons     [gamepadButtonCount]bool  // Button state of the current frame
+	prevButtons [gamepadButtonCount]bool  // Button state of the previous frame
+	axes        [gamepadAxisCount]float32 // Axis state of the current frame
+}
+
+// Creates a Gamepad for the given glfw joystick, with the default mapping and deadzone.
+func NewGamepad(joystick glfw.Joystick) *Gamepad {
+	return &Gamepad{
+		Joystick: joystick,
+		Mapping:  DefaultGamepadMapping,
+		Deadzone: defaultGamepadDeadzone,
+	}
+}
+
+// Reads the current button and axis state of the controller. Input.PollInput() calls this
+// for all gamepads, so only call it yourself when using a Gamepad without Input.
+func (gamepad *Gamepad) Poll() {
+	gamepad.prevButtons = gamepad.buttons
+
+	gamepad.Connected = glfw.JoystickPresent(gamepad.Joystick)
+	if !gamepad.Connected {
+		gamepad.Name = ""
+		gamepad.buttons = [gamepadButtonCount]bool{}
+		gamepad.axes = [gamepadAxisCount]float32{}
+		return
+	}
+	gamepad.Name = glfw.GetJoystickName(gamepad.Joystick)
+
+	rawButtons := glfw.GetJoystickButtons(gamepad.Joystick)
+	for button, index := range gamepad.Mapping.Buttons {
+		gamepad.buttons[button] = index >= 0 && index < len(rawButtons) && glfw.Action(rawButtons[index]) == glfw.Press
+	}
+
+	rawAxes := glfw.GetJoystickAxes(gamepad.Joystick)
+	for axis, index := range gamepad.Mapping.Axes {
+		if index < 0 || index >= len(rawAxes) {
+			// The controller doesn't have this axis, report it as released/centered
+			gamepad.axes[axis] = 0
+			continue
+		}
+		value := rawAxes[index]
+		if GamepadAxis(axis) == GamepadLeftTrigger || GamepadAxis(axis) == GamepadRightTrigger {
+			// Triggers are reported as -1 (released) .. 1, make that 0 .. 1
+			value = (value + 1) / 2
+		} else if value > -gamepad.Deadzone && value < gamepad.Deadzone {
+			value = 0
+		}
+		gamepad.axes[axis] = value
+	}
+}
+
+// Returns true as long as the button is held down.
+func (gamepad *Gamepad) IsButtonDown(button GamepadButton) bool {
+	if button < 0 || button >= gamepadButtonCount {
+		return false
+	}
+	return gamepad.buttons[button]
+}
+
+// Returns true only in the frame that the button went down.
+func (gamepad *Gamepad) IsButtonJustPressed(button GamepadButton) bool {
+	if button < 0 || button >= gamepadButtonCount {
+		return false
+	}
+	return gamepad.buttons[button] && !gamepad.prevButtons[button]
+}
+
+// Returns true only in the frame that the button was let go.
+func (gamepad *Gamepad) IsButtonJustReleased(button GamepadButton) bool {
+	if button < 0 || button >= gamepadButtonCount {
+		return false
+	}
+	return !gamepad.buttons[button] && gamepad.prevButtons[button]
+}
+
+// Returns the value of the axis, see GamepadAxis for the ranges. 0 when the controller is disconnected.
+func (gamepad *Gamepad) Axis(axis GamepadAxis) float32 {
+	if axis < 0 || axis >= gamepadAxisCount {
+		return 0
+	}
+	return gamepad.axes[axis]
+}
@@ -1,14 +1,15 @@
 package gogl
 
 import (
+	"fmt"
+	"image"
 	"log"
 	"runtime"
 	"strings"
+	"unsafe"
 
 	//"path/filepath"
 
-	"errors"
-
 	"github.com/go-gl/gl/v4.5-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
@@ -17,44 +18,201 @@ import (
 // [ Init functions ]
 
 /* Inits GL and GLFW. Creates a window in the process with given dimensions. */
-func Init(windowTitle string, width, height int) *glfw.Window {
+func Init(windowTitle string, width, height int) (*glfw.Window, error) {
+	return initWindow(windowTitle, width, height, true)
+}
+
+/*
+Same as Init(), but the window is never shown. The GL context works as usual, so this is meant for
+rendering offscreen into a Framebuffer, e.g. for golden-image tests in CI.
+Note that GLFW still needs a display server (on Linux CI, run under xvfb-run or similar).
+*/
+func InitHidden(windowTitle string, width, height int) (*glfw.Window, error) {
+	return initWindow(windowTitle, width, height, false)
+}
+
+func initWindow(windowTitle string, width, height int, visible bool) (*glfw.Window, error) {
 	runtime.LockOSThread()
 
-	window := InitGlfw(windowTitle, width, height)
+	window, err := initGlfw(windowTitle, width, height, visible)
+	if err != nil {
+		return nil, err
+	}
 
 	// init OpenGL
 	if err := gl.Init(); err != nil {
-		panic(err)
+		glfw.Terminate()
+		return nil, fmt.Errorf("failed to initialize OpenGL: %w", err)
 	}
 
 	PrintGLVersion()
 	PrintGLFWVersion()
 
+	return window, nil
+}
+
+/* Same as Init(), but panics on failure. Handy for quick experiments. */
+func MustInit(windowTitle string, width, height int) *glfw.Window {
+	window, err := Init(windowTitle, width, height)
+	if err != nil {
+		panic(err)
+	}
 	return window
 }
 
 /* initializes glfw and returns a Window to use. */
-func InitGlfw(windowTitle string, width, height int) *glfw.Window {
+func InitGlfw(windowTitle string, width, height int) (*glfw.Window, error) {
+	return initGlfw(windowTitle, width, height, true)
+}
+
+func initGlfw(windowTitle string, width, height int, visible bool) (*glfw.Window, error) {
 	if err := glfw.Init(); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to initialize GLFW: %w", err)
 	}
 	glfw.WindowHint(glfw.Resizable, glfw.False)
 	glfw.WindowHint(glfw.ContextVersionMajor, 4)
 	glfw.WindowHint(glfw.ContextVersionMinor, 5)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	glfw.WindowHint(glfw.DepthBits, 24)  // Make sure the default framebuffer has a depth buffer
+	glfw.WindowHint(glfw.StencilBits, 8) // ... and a stencil buffer
+	if visible {
+		glfw.WindowHint(glfw.Visible, glfw.True)
+	} else {
+		glfw.WindowHint(glfw.Visible, glfw.False)
+	}
 
 	window, err := glfw.CreateWindow(width, height, windowTitle, nil, nil)
 	if err != nil {
-		panic(err)
+		glfw.Terminate()
+		return nil, fmt.Errorf("failed to create window: %w", err)
 	}
 	window.MakeContextCurrent()
 
-	return window
+	return window, nil
 }
 
 // [ / Init functions ]
 // ------------------------------------------------------------------------------------------
+// [ Window functions ]
+
+// Returns the size of the window in screen coordinates. On HiDPI screens this is not
+// the same as the number of pixels, use FramebufferSize() for anything GL related.
+func WindowSize(w *glfw.Window) (int, int) {
+	return w.GetSize()
+}
+
+// Returns the size of the window's framebuffer in pixels. This is the size to use for
+// the viewport and any other pixel math.
+func FramebufferSize(w *glfw.Window) (int, int) {
+	return w.GetFramebufferSize()
+}
+
+// Returns the ratio between the framebuffer size and the window size, horizontally and vertically.
+// Multiply window coordinates (e.g. the cursor position) by this to get framebuffer pixels.
+// This is NOT the OS content scale (the user's display scaling setting): GLFW 3.2 has no
+// glfw.GetContentScale (that is 3.3). On macOS Retina screens the two are the same (2), but on Windows and
+// Linux the window size is already in pixels, so this returns 1 there, also on a scaled 4K screen.
+// So it doesn't tell you how much to enlarge UI or text for such screens.
+func FramebufferScale(w *glfw.Window) (float32, float32) {
+	windowWidth, windowHeight := w.GetSize()
+	framebufferWidth, framebufferHeight := w.GetFramebufferSize()
+	if windowWidth == 0 || windowHeight == 0 {
+		// Minimized
+		return 1, 1
+	}
+	return float32(framebufferWidth) / float32(windowWidth), float32(framebufferHeight) / float32(windowHeight)
+}
+
+// Calls f with the new framebuffer size (in pixels) whenever it changes, after setting the viewport to it.
+// Use it to rebuild size dependent things, like an Ortho2D() projection or a Framebuffer.
+// Replaces any framebuffer size callback that was set on the window before. f may be nil.
+// Note that Init() creates a window that can't be resized by the user; the framebuffer can still
+// change size through w.SetSize(), fullscreen, or moving the window to a screen with another scale.
+func OnResize(w *glfw.Window, f func(width, height int)) {
+	w.SetFramebufferSizeCallback(func(w *glfw.Window, width, height int) {
+		SetViewportRect(0, 0, width, height)
+		if f != nil {
+			f(width, height)
+		}
+	})
+}
+
+// Sets the icon of the window (title bar, taskbar) from one or more png files. Pass a few sizes
+// (e.g. 16x16, 32x32 and 48x48), and the system picks the one closest to what it needs.
+// On macOS the window icon can't be set, and this does nothing.
+func SetWindowIcon(w *glfw.Window, paths ...string) error {
+	icons := make([]image.Image, len(paths))
+	for i, path := range paths {
+		img, err := loadImage(path)
+		if err != nil {
+			return err
+		}
+		// GLFW wants straight alpha RGBA in image orientation (rows top to bottom)
+		pixels, dimensions := pixelDataFromImage(img, false)
+		icons[i] = &image.NRGBA{
+			Pix:    pixels,
+			Stride: dimensions[0] * 4,
+			Rect:   image.Rect(0, 0, dimensions[0], dimensions[1]),
+		}
+	}
+	w.SetIcon(icons)
+	return nil
+}
+
+// Sets how the cursor behaves over the window:
+//   - "normal": visible, and free to leave the window
+//   - "hidden": invisible while over the window, but free to leave it
+//   - "disabled": invisible and captured by the window, with unlimited movement, for e.g. a first-person camera.
+//     Read the movement from the change in cursor position every frame.
+//
+// Note that GLFW 3.2 has no raw mouse motion (glfw.RawMouseMotion is 3.3), so "disabled" still gets
+// the cursor movement with the OS acceleration applied.
+func SetCursorMode(w *glfw.Window, mode string) error {
+	switch mode {
+	case "normal":
+		w.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	case "hidden":
+		w.SetInputMode(glfw.CursorMode, glfw.CursorHidden)
+	case "disabled":
+		w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	default:
+		return fmt.Errorf("unknown cursor mode %q, use \"normal\", \"hidden\" or \"disabled\"", mode)
+	}
+	return nil
+}
+
+// Moves the window so its top-left corner (excluding the title bar) is at x, y in screen coordinates.
+func SetWindowPos(w *glfw.Window, x, y int) {
+	w.SetPos(x, y)
+}
+
+// Centers the window on the primary monitor.
+// GLFW 3.2 can't tell the work area of a monitor (glfw.GetWorkarea is 3.3), so this centers on the
+// whole monitor, taskbars included.
+func CenterWindow(w *glfw.Window) {
+	monitor := glfw.GetPrimaryMonitor()
+	if monitor == nil {
+		return
+	}
+	mode := monitor.GetVideoMode()
+	monitorX, monitorY := monitor.GetPos()
+	width, height := w.GetSize()
+	SetWindowPos(w, monitorX+(mode.Width-width)/2, monitorY+(mode.Height-height)/2)
+}
+
+// Shows what was drawn this frame, by swapping the back buffer (that we draw into) with the front buffer.
+func SwapBuffers(w *glfw.Window) {
+	w.SwapBuffers()
+}
+
+// Processes the pending window events (keyboard, mouse, resizing, closing). Call once per frame.
+func PollEvents() {
+	glfw.PollEvents()
+}
+
+// [/ Window functions ]
+// ------------------------------------------------------------------------------------------
 // [ Makers ]
 
 // Creates a generic Buffer Object in GL, returns its ID.
@@ -73,21 +231,37 @@ func GenVertexArray() VAOID {
 	return VAOID(vaoID)
 }
 
+// A slightly more intelligent/go version of gl.BufferData, for a slice of any fixed-size type
+// (e.g. float32, uint16, int8, or a struct of those). The size in bytes is worked out from the type.
+// Typical target: gl.ARRAY_BUFFER, gl.ELEMENT_ARRAY_BUFFER
+// Typical usage: gl.STATIC_DRAW
+func BufferData[T any](data []T, target uint32, usage uint32) {
+	if len(data) == 0 {
+		// gl.Ptr can't point into an empty slice, so just allocate an empty buffer
+		gl.BufferData(target, 0, nil, usage)
+		return
+	}
+	var zero T
+	gl.BufferData(target, int(unsafe.Sizeof(zero))*len(data), gl.Ptr(data), usage)
+}
+
 // A slightly more intelligent/go version of gl.BufferData.
 // Typical target: gl.ARRAY_BUFFER
 // Typical usage: gl.STATIC_DRAW
 func BufferDataFloat32(data []float32, target uint32, usage uint32) {
-	gl.BufferData(target, 4*len(data), gl.Ptr(data), usage)
+	BufferData(data, target, usage)
 }
 
 // A slightly more intelligent/go version of gl.BufferData.
 // Typical target: gl.ELEMENT_ARRAY_BUFFER
 // Typical usage: gl.STATIC_DRAW
 func BufferDataUint32(data []uint32, target uint32, usage uint32) {
-	gl.BufferData(target, 4*len(data), gl.Ptr(data), usage)
+	BufferData(data, target, usage)
 }
 
 // Creates shadersource, compiles it, and checks for errors in that process.
+// The source is taken as is, so this is the path to use for generated or embedded shaders:
+// these are not added to the hotloading watchlist. Use MakeShaderFromFile() for shader files.
 func MakeShader(shaderSourceCode string, shaderType uint32) (ShaderID, error) {
 	// We need to convert the shaderSource from a Go string to
 	// a C string. C strings need a null byte at the end, and
@@ -108,37 +282,61 @@ func MakeShader(shaderSourceCode string, shaderType uint32) (ShaderID, error) {
 	// Check for error
 	err := CheckShaderCompileSuccess(ShaderID(shaderId), shaderSourceCode)
 	if err != nil {
+		gl.DeleteShader(shaderId)
 		return 0, err
 	}
 
 	return ShaderID(shaderId), nil
 }
 
+// Reads the shader file at path, compiles it, and adds the file to the hotloading watchlist,
+// so that programs using it are rebuilt when it changes (see HotloadShaders()).
+func MakeShaderFromFile(path string, shaderType uint32) (ShaderID, error) {
+	return LoadShader(path, shaderType)
+}
+
 // [/ Makers ]
 // ------------------------------------------------------------------------------------------
 // [ Status checkers ]
 
-// Return an error when errors are found in linking shaders to given program.
+// The error returned when a shader fails to compile, or a program fails to link.
+// Use errors.As() to get at the fields, e.g. to show the log in an editor.
+type ShaderError struct {
+	Stage    string           // "vertex", "fragment", "geometry", "compute", ... for compile errors, "link" for link errors, "validate" for validation errors (TryCompileProgram())
+	FilePath string           // The shader file, empty when unknown (e.g. for shaders made from a string, and most link errors)
+	Source   string           // The source that was compiled, after preprocessing. The line numbers in Log refer to this. Empty for link and validation errors.
+	Log      string           // The info log of the driver
+	Entries  []ShaderLogEntry // The lines of Log, with the line numbers picked out where the format is recognized (see shaderlog.go)
+}
+
+func (err *ShaderError) Error() string {
+	if err.Stage == "link" {
+		if err.FilePath != "" {
+			return "failed to link program " + err.FilePath + ": \n" + err.Log
+		}
+		return "failed to link program: \n" + err.Log
+	}
+	if err.Stage == "validate" {
+		return "failed to validate program: \n" + err.Log
+	}
+	if err.FilePath != "" {
+		return "failed to compile " + err.Stage + " shader " + err.FilePath + ": \n" + err.Log
+	}
+	return "failed to compile " + err.Stage + " shader: \n" + err.Log
+}
+
+// Return an error (a *ShaderError) when errors are found in linking shaders to given program.
 func CheckProgramLinkSuccess(programID ProgramID) error {
 	var success int32
 	gl.GetProgramiv(uint32(programID), gl.LINK_STATUS, &success)
 	if success == gl.FALSE {
-		// Set log length
-		var logLength int32
-		gl.GetShaderiv(uint32(programID), gl.INFO_LOG_LENGTH, &logLength)
-
-		// Make log variable with correct length
-		log := strings.Repeat("\x00", int(logLength+1))
-
-		// Fetch log data (put it in log)
-		gl.GetShaderInfoLog(uint32(programID), logLength, nil, gl.Str(log))
-
-		return errors.New("failed to link program: \n" + log)
+		infoLog := programInfoLog(programID)
+		return &ShaderError{Stage: "link", Log: infoLog, Entries: parseShaderLog(infoLog)}
 	}
 	return nil
 }
 
-// Return an error when errors are found in compiling given shader.
+// Return an error (a *ShaderError) when errors are found in compiling given shader.
 func CheckShaderCompileSuccess(shaderID ShaderID, shaderSource string) error {
 	var success int32
 	gl.GetShaderiv(uint32(shaderID), gl.COMPILE_STATUS, &success)
@@ -153,11 +351,74 @@ func CheckShaderCompileSuccess(shaderID ShaderID, shaderSource string) error {
 		// Fetch log data (put it in log)
 		gl.GetShaderInfoLog(uint32(shaderID), logLength, nil, gl.Str(log))
 
-		return errors.New("failed to compile " + shaderSource + ", " + log)
+		var shaderType int32
+		gl.GetShaderiv(uint32(shaderID), gl.SHADER_TYPE, &shaderType)
+
+		infoLog := strings.TrimRight(log, "\x00")
+		return &ShaderError{
+			Stage:   shaderStageName(uint32(shaderType)),
+			Source:  strings.TrimSuffix(shaderSource, "\x00"),
+			Log:     infoLog,
+			Entries: parseShaderLog(infoLog),
+		}
 	}
 	return nil
 }
 
+// Returns the name of the shader stage, as used in ShaderError.Stage.
+func shaderStageName(shaderType uint32) string {
+	switch shaderType {
+	case gl.VERTEX_SHADER:
+		return "vertex"
+	case gl.FRAGMENT_SHADER:
+		return "fragment"
+	case gl.GEOMETRY_SHADER:
+		return "geometry"
+	case gl.TESS_CONTROL_SHADER:
+		return "tessellation control"
+	case gl.TESS_EVALUATION_SHADER:
+		return "tessellation evaluation"
+	case gl.COMPUTE_SHADER:
+		return "compute"
+	}
+	return fmt.Sprintf("unknown (0x%x)", shaderType)
+}
+
+// Checks gl.GetError, and returns an error tagged with the given label if something went wrong.
+// E.g.: `if err := CheckGLError("draw sprites"); err != nil { log.Println(err) }`
+// GL keeps a queue of errors, so all pending errors are collected.
+func CheckGLError(tag string) error {
+	errorNames := []string{}
+	for code := gl.GetError(); code != gl.NO_ERROR; code = gl.GetError() {
+		errorNames = append(errorNames, glErrorName(code))
+	}
+	if len(errorNames) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: gl error: %s", tag, strings.Join(errorNames, ", "))
+}
+
+// Returns a readable name for a gl.GetError code.
+func glErrorName(code uint32) string {
+	switch code {
+	case gl.INVALID_ENUM:
+		return "INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		return "INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		return "INVALID_OPERATION"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		return "INVALID_FRAMEBUFFER_OPERATION"
+	case gl.OUT_OF_MEMORY:
+		return "OUT_OF_MEMORY"
+	case gl.STACK_UNDERFLOW:
+		return "STACK_UNDERFLOW"
+	case gl.STACK_OVERFLOW:
+		return "STACK_OVERFLOW"
+	}
+	return fmt.Sprintf("0x%x", code)
+}
+
 // [/ Status checkers ]
 // ------------------------------------------------------------------------------------------
 // [ Type-Aware Wrappers ]
@@ -172,13 +433,251 @@ func LinkProgram(programID ProgramID) {
 	gl.LinkProgram(uint32(programID))
 }
 
-// Simple type aware wrapper for gl.UseProgram
+// The program that was last bound with UseProgram(), so we can skip binding it again.
+// Only valid when programStateKnown is true; 0 is a valid program to bind, so it can't mean "unknown".
+var currentProgramID ProgramID
+var programStateKnown bool
+
+// Simple type aware wrapper for gl.UseProgram.
+// Skips the call when the program is already in use. If you call gl.UseProgram directly,
+// call ResetProgramState() afterwards, so this function doesn't skip a needed bind.
 func UseProgram(programID ProgramID) {
+	if programStateKnown && programID == currentProgramID {
+		return
+	}
 	gl.UseProgram(uint32(programID))
+	currentProgramID = programID
+	programStateKnown = true
+}
+
+// Forgets which program is in use, so that the next UseProgram() always binds.
+func ResetProgramState() {
+	programStateKnown = false
 }
 
 // [/ Type-Aware Wrappers ]
 // ------------------------------------------------------------------------------------------
+// [ Render state ]
+
+// Sets the viewport to cover the whole window. Uses the framebuffer size, which is
+// in pixels and can differ from the window size on HiDPI screens.
+// Call this again after the window (framebuffer) has been resized.
+func SetViewport(window *glfw.Window) {
+	width, height := FramebufferSize(window)
+	SetViewportRect(0, 0, width, height)
+}
+
+// Simple type aware wrapper for gl.Viewport. Coordinates are in pixels, with the origin at the bottom-left.
+func SetViewportRect(x, y, width, height int) {
+	gl.Viewport(int32(x), int32(y), int32(width), int32(height))
+}
+
+// Sets the color that Clear() fills the screen with.
+func SetClearColor(r, g, b, a float32) {
+	gl.ClearColor(r, g, b, a)
+}
+
+// When true, Clear() also clears the stencil buffer. EnableStencil() turns this on.
+var ClearStencil bool
+
+// Clears the color and depth buffers (and the stencil buffer if ClearStencil is set).
+// Typically called at the start of every frame.
+func Clear() {
+	mask := uint32(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	if ClearStencil {
+		mask |= gl.STENCIL_BUFFER_BIT
+	}
+	gl.Clear(mask)
+}
+
+// Makes GL convert the (linear) colors the fragment shader outputs to sRGB when writing them to
+// the framebuffer, for correct gamma. Pair this with TextureOptions.SRGB for the textures.
+func EnableSRGBFramebuffer() {
+	gl.Enable(gl.FRAMEBUFFER_SRGB)
+}
+
+// Stops the linear to sRGB conversion on write.
+func DisableSRGBFramebuffer() {
+	gl.Disable(gl.FRAMEBUFFER_SRGB)
+}
+
+// Enables alpha blending, so that transparent parts of textures are see-through.
+// Uses the standard "straight alpha" blend function (SRC_ALPHA, ONE_MINUS_SRC_ALPHA).
+func EnableBlending() {
+	EnableBlendingFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+}
+
+// Enables blending with custom source and destination factors.
+// E.g.: EnableBlendingFunc(gl.ONE, gl.ONE) for additive blending.
+func EnableBlendingFunc(srcFactor, dstFactor uint32) {
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(srcFactor, dstFactor)
+}
+
+// Disables blending, everything is drawn opaque again.
+func DisableBlending() {
+	gl.Disable(gl.BLEND)
+}
+
+// Blend mode presets for SetBlendMode().
+type BlendMode int
+
+const (
+	BlendAlpha         BlendMode = iota // Regular transparency, for straight alpha textures (same as EnableBlending())
+	BlendAdditive                       // Adds the color on top (scaled by alpha), for glow, fire, lasers
+	BlendMultiply                       // Multiplies the color below with the drawn color, for shadows and tinting. White is a no-op, so make transparent parts white.
+	BlendPremultiplied                  // Regular transparency, for textures whose colors are already multiplied by alpha
+)
+
+// Enables blending with one of the presets. Also resets the blend equation to gl.FUNC_ADD.
+func SetBlendMode(mode BlendMode) {
+	gl.BlendEquation(gl.FUNC_ADD)
+	switch mode {
+	case BlendAdditive:
+		EnableBlendingFunc(gl.SRC_ALPHA, gl.ONE)
+	case BlendMultiply:
+		EnableBlendingFunc(gl.DST_COLOR, gl.ZERO)
+	case BlendPremultiplied:
+		EnableBlendingFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+	default:
+		EnableBlendingFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
+
+// Enables depth testing, so that fragments closer to the camera hide the ones behind them,
+// regardless of draw order. Uses gl.LESS as the depth function.
+// Note that the depth buffer has to be cleared every frame (gl.DEPTH_BUFFER_BIT), Clear() does this.
+func EnableDepthTest() {
+	EnableDepthTestFunc(gl.LESS)
+}
+
+// Enables depth testing with a custom depth function. E.g.: gl.LEQUAL, gl.GREATER.
+func EnableDepthTestFunc(depthFunc uint32) {
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(depthFunc)
+}
+
+// Disables depth testing, fragments are drawn in the order they are rendered.
+func DisableDepthTest() {
+	gl.Disable(gl.DEPTH_TEST)
+}
+
+// Enables or disables face culling.
+// mode: which faces to throw away, gl.BACK, gl.FRONT or gl.FRONT_AND_BACK (0 defaults to gl.BACK)
+// frontFace: which winding counts as the front, gl.CCW or gl.CW (0 defaults to gl.CCW)
+func SetCullFace(enabled bool, mode uint32, frontFace uint32) {
+	if !enabled {
+		gl.Disable(gl.CULL_FACE)
+		return
+	}
+	if mode == 0 {
+		mode = gl.BACK
+	}
+	if frontFace == 0 {
+		frontFace = gl.CCW
+	}
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(mode)
+	gl.FrontFace(frontFace)
+}
+
+// Enables the stencil test, and makes Clear() clear the stencil buffer as well.
+// Typical masking: draw the mask with SetStencilFunc(gl.ALWAYS, 1, 0xFF) and SetStencilOp(gl.KEEP, gl.KEEP, gl.REPLACE),
+// then draw the masked content with SetStencilFunc(gl.EQUAL, 1, 0xFF) and SetStencilOp(gl.KEEP, gl.KEEP, gl.KEEP).
+func EnableStencil() {
+	gl.Enable(gl.STENCIL_TEST)
+	ClearStencil = true
+}
+
+// Disables the stencil test, and stops Clear() from clearing the stencil buffer.
+func DisableStencil() {
+	gl.Disable(gl.STENCIL_TEST)
+	ClearStencil = false
+}
+
+// Sets when a fragment passes the stencil test.
+// compareFunc: gl.ALWAYS, gl.NEVER, gl.EQUAL, gl.NOTEQUAL, gl.LESS, ... compares (ref & mask) with (stencil & mask).
+func SetStencilFunc(compareFunc uint32, ref int32, mask uint32) {
+	gl.StencilFunc(compareFunc, ref, mask)
+}
+
+// Sets what happens to the stencil value when the stencil test fails, when the stencil test passes
+// but the depth test fails, and when both pass. E.g.: gl.KEEP, gl.ZERO, gl.REPLACE, gl.INCR, gl.INVERT.
+func SetStencilOp(stencilFail, depthFail, pass uint32) {
+	gl.StencilOp(stencilFail, depthFail, pass)
+}
+
+// Sets which bits of the stencil buffer can be written to. 0x00 makes the stencil buffer read-only.
+func SetStencilMask(mask uint32) {
+	gl.StencilMask(mask)
+}
+
+// Enables polygon offset for filled polygons, and sets it. The depth of the drawn fragments is pushed back by
+// factor * (depth slope of the polygon) + units * (smallest resolvable depth difference). Negative values pull
+// them forward instead, e.g. SetPolygonOffset(-1, -1) for decals and outlines on top of coplanar geometry.
+func SetPolygonOffset(factor, units float32) {
+	EnablePolygonOffset()
+	gl.PolygonOffset(factor, units)
+}
+
+// Enables polygon offset for filled polygons, with the offset last set by SetPolygonOffset().
+func EnablePolygonOffset() {
+	gl.Enable(gl.POLYGON_OFFSET_FILL)
+}
+
+// Disables polygon offset, so depth values are used as-is again.
+func DisablePolygonOffset() {
+	gl.Disable(gl.POLYGON_OFFSET_FILL)
+}
+
+// Enables the scissor test, so only the given rectangle of the framebuffer can be drawn to (and cleared).
+// Handy for clipping scrollable UI panels.
+// Note that the coordinates are in framebuffer pixels, with the origin at the bottom-left. To convert
+// a rectangle with a top-left origin: SetScissor(x, framebufferHeight-y-height, width, height).
+// On HiDPI screens, multiply cursor/window coordinates by FramebufferScale() first.
+func SetScissor(x, y, width, height int) {
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(int32(x), int32(y), int32(width), int32(height))
+}
+
+// Disables the scissor test, so the whole framebuffer can be drawn to again.
+func DisableScissor() {
+	gl.Disable(gl.SCISSOR_TEST)
+}
+
+// Lets the vertex shader set the size of points (GOGL_POINTS) through gl_PointSize.
+// Without this, all points are drawn 1 pixel big.
+func EnableProgramPointSize() {
+	gl.Enable(gl.PROGRAM_POINT_SIZE)
+}
+
+// Switches between drawing only the edges of triangles (true), or filling them (false).
+// Handy for debugging mesh topology.
+func SetWireframe(on bool) {
+	if on {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	} else {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+}
+
+// Sets the width of lines in pixels (also used for the edges in SetWireframe()).
+// Many drivers only support a width of 1 in the core profile, and a forward compatible context
+// (which Init() creates) may even reject widths above 1. Check LineWidthRange() for what is supported;
+// for thick lines that work everywhere, expand the lines to quads (e.g. in a geometry shader) instead.
+func SetLineWidth(width float32) {
+	gl.LineWidth(width)
+}
+
+// Returns the smallest and largest line width the driver supports for SetLineWidth().
+func LineWidthRange() (float32, float32) {
+	var widthRange [2]float32
+	gl.GetFloatv(gl.ALIASED_LINE_WIDTH_RANGE, &widthRange[0])
+	return widthRange[0], widthRange[1]
+}
+
+// [/ Render state ]
+// ------------------------------------------------------------------------------------------
 // [ Log functions ]
 
 func GetVersion() string {
@@ -0,0 +1,44 @@
+package gogl
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestShaderErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  ShaderError
+		want string
+	}{
+		{ShaderError{Stage: "fragment", Log: "log"}, "failed to compile fragment shader: \nlog"},
+		{ShaderError{Stage: "vertex", FilePath: "a.vert", Log: "log"}, "failed to compile vertex shader a.vert: \nlog"},
+		{ShaderError{Stage: "link", Log: "log"}, "failed to link program: \nlog"},
+		{ShaderError{Stage: "link", FilePath: "a.shader", Log: "log"}, "failed to link program a.shader: \nlog"},
+		{ShaderError{Stage: "validate", Log: "log"}, "failed to validate program: \nlog"},
+	}
+	for _, test := range tests {
+		err := test.err
+		if got := err.Error(); got != test.want {
+			t.Errorf("%+v: got %q, want %q", test.err, got, test.want)
+		}
+	}
+}
+
+// Wrapped shader errors can still be picked out with errors.As, and withShaderFilePath fills in the path.
+func TestWithShaderFilePath(t *testing.T) {
+	err := withShaderFilePath(fmt.Errorf("loading: %w", &ShaderError{Stage: "vertex"}), "a.vert")
+
+	var shaderErr *ShaderError
+	if !errors.As(err, &shaderErr) {
+		t.Fatalf("errors.As found no *ShaderError in %v", err)
+	}
+	if shaderErr.FilePath != "a.vert" {
+		t.Errorf("FilePath is %q, want %q", shaderErr.FilePath, "a.vert")
+	}
+
+	plain := errors.New("file not found")
+	if got := withShaderFilePath(plain, "a.vert"); got != plain {
+		t.Errorf("other errors should be returned as is, got %v", got)
+	}
+}
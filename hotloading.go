@@ -8,6 +8,9 @@ package gogl
 	and it will load them in upon saving - without the need of recompiling the entire
 	program.
 
+	Textures loaded with LoadImageToTexture() are tracked the same way, and are
+	reloaded into their existing TextureID by HotloadTextures().
+
 	When the compilation of one or more of the shaders fails, the programs using them will 
 	continue running on the previous shader compilations. An error will be logged in the
 	terminal.
@@ -18,9 +21,9 @@ package gogl
 */
 
 import (
+	"fmt"
 	"time"
 	"os"
-	"io/ioutil"
 	"log"
 	"github.com/go-gl/gl/v4.5-core/gl"
 )
@@ -30,6 +33,11 @@ var (
 	// so that we can rebuild upon shader change
 	LoadedShaders []ShaderFileInfo					// used by GetChangedShaderFiles()
 	LoadedPrograms = make(map[string]*Program)		// used by HotloadShaders()
+	LoadedTextures []TextureFileInfo				// used by HotloadTextures()
+
+	// Throttling of HotloadShaders(), see SetHotloadCheckInterval()
+	hotloadCheckInterval time.Duration
+	lastHotloadCheck time.Time
 )
 
 type ShaderFileInfo struct {
@@ -37,8 +45,30 @@ type ShaderFileInfo struct {
 	LastModified time.Time
 }
 
+type TextureFileInfo struct {
+	FilePath string
+	LastModified time.Time
+	Texture TextureID
+	Options TextureOptions
+	Placeholder bool // The file failed to load, Texture holds the missing texture until it loads
+}
+
+// Makes HotloadShaders() only check the shader files once per interval, no matter how often
+// it is called. This way it can be called every frame without hammering the filesystem.
+// The default of 0 checks on every call.
+func SetHotloadCheckInterval(interval time.Duration){
+	hotloadCheckInterval = interval
+}
+
 // <toplevel function>
 func HotloadShaders(){
+	// Skip if we checked less than hotloadCheckInterval ago
+	now := time.Now()
+	if now.Sub(lastHotloadCheck) < hotloadCheckInterval {
+		return
+	}
+	lastHotloadCheck = now
+
 	// Check all shader files for changes (by LastModified date)
 	// This will update LastModified in LoadedShaders for each
 	// ShaderFileInfo struct, and thus will only work once per change. 
@@ -64,8 +94,7 @@ func ReloadProgram(programName string, storedProgramPtr *Program, changedShaderF
 	// Check if any changed files are related to our program
 	needsRebuilding := false
 	for i := range changedShaderFiles {
-		if changedShaderFiles[i] == (*storedProgramPtr).VertexShaderFilePath || 
-		   changedShaderFiles[i] == (*storedProgramPtr).FragmentShaderFilePath {
+		if programUsesShaderFile(storedProgramPtr, changedShaderFiles[i]) {
 			needsRebuilding = true
 			log.Printf("Program %s (%d) needs rebuiding", programName, (*storedProgramPtr).ID)
 			break
@@ -74,23 +103,50 @@ func ReloadProgram(programName string, storedProgramPtr *Program, changedShaderF
 
 	// Rebuild
 	if needsRebuilding {
-		// Save old id, so we can remove the old program when the new one is compiled
-		oldProgramID := (*storedProgramPtr).ID
+		return rebuildProgram(programName, storedProgramPtr)
+	}
 
-		// Try make a new program (this will update the ProgramID in the current struct)
-		// So we start using it immediately if the compilation succeeds
-		_, err := MakeProgram(programName, (*storedProgramPtr).VertexShaderFilePath, (*storedProgramPtr).FragmentShaderFilePath)
-		if err != nil {
-			// Handle error, and continue using old program
-			log.Printf("Failed to build program %s, continuing to use old compilation (%d). \n", programName, (*storedProgramPtr).ID)
-			return err
-		}
+	// Done
+	return nil
+}
 
-		// Remove old program
-		gl.DeleteProgram(uint32(oldProgramID))
+// Rebuilds the program with the given name right away, whether its shader files changed or not.
+// Handy for an explicit "recompile now" hotkey.
+func ReloadProgramByName(programName string) error{
+	storedProgramPtr, ok := LoadedPrograms[programName]
+	if ok == false {
+		return fmt.Errorf("no program named %s is loaded", programName)
 	}
+	log.Printf("Program %s (%d) rebuild requested", programName, (*storedProgramPtr).ID)
+	return rebuildProgram(programName, storedProgramPtr)
+}
+
+// Compiles the program again from its shader files. On failure the old compilation stays in use.
+func rebuildProgram(programName string, storedProgramPtr *Program) error{
+	// Save old id, so we can remove the old program when the new one is compiled
+	oldProgramID := (*storedProgramPtr).ID
+
+	// Try make a new program (this will update the ProgramID in the current struct)
+	// So we start using it immediately if the compilation succeeds
+	var err error
+	if (*storedProgramPtr).CombinedShaderFile {
+		// One file holds both stages
+		_, err = MakeProgramFromCombinedFile(programName, (*storedProgramPtr).VertexShaderFilePath)
+	} else {
+		_, err = MakeProgram(programName, (*storedProgramPtr).VertexShaderFilePath, (*storedProgramPtr).FragmentShaderFilePath)
+	}
+	if err != nil {
+		// Handle error, and continue using old program
+		log.Printf("Failed to build program %s, continuing to use old compilation (%d). \n", programName, (*storedProgramPtr).ID)
+		return err
+	}
+
+	// Remove old program
+	gl.DeleteProgram(uint32(oldProgramID))
+
+	// The id changed, so make sure the next UseProgram() binds the new one
+	ResetProgramState()
 
-	// Done
 	return nil
 }
 
@@ -115,18 +171,45 @@ func GetChangedShaderFiles() []string{
 }
 
 func LoadShader(path string, shaderType uint32) (ShaderID, error){
-	shaderFileData, err := ioutil.ReadFile(path)
+	// Read the file, and inline its #include's (see preprocessor.go)
+	shaderFileStr, includes, err := preprocessShaderFile(path)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 
-	shaderFileStr := string(shaderFileData)
 	shaderID, err := MakeShader(shaderFileStr, shaderType)
 	if err != nil {
-		return 0, err
+		// Tell which file failed
+		return 0, withShaderFilePath(err, path)
+	}
+
+	// Add to watchlist if not yet a member, together with the files it includes
+	addShaderToWatchList(path)
+	registerShaderIncludes(path, includes)
+
+	return shaderID, nil
+}
+
+// Remembers which files the shader includes, and watches them, so that changing them rebuilds the shader.
+func registerShaderIncludes(path string, includes []string) {
+	ShaderIncludes[path] = includes
+	for _, include := range includes {
+		addShaderToWatchList(include)
 	}
+}
 
-	// Add to watchlist if not yet a member
+// Returns true if the program is built from the given shader file, either directly or through an #include.
+func programUsesShaderFile(program *Program, path string) bool {
+	for _, shaderPath := range []string{program.VertexShaderFilePath, program.FragmentShaderFilePath} {
+		if shaderPath == path || containsString(ShaderIncludes[shaderPath], path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds the shader file to the watchlist, if it isn't in there already.
+func addShaderToWatchList(path string) {
 	if shaderIsInWatchList(path) == false {
 		// Get Last Modified time
 		file, err := os.Stat(path)
@@ -140,8 +223,6 @@ func LoadShader(path string, shaderType uint32) (ShaderID, error){
 		}
 		LoadedShaders = append(LoadedShaders, shaderFileInfo)
 	}
-
-	return shaderID, nil
 }
 
 // Used to check if MakeShader() should add the path of the shader
@@ -153,4 +234,134 @@ func shaderIsInWatchList(path string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// Returns true if the shader file is in the watchlist, so changes to it are hotloaded.
+// The path has to be given the same way as it was loaded (e.g. both relative).
+func IsWatched(path string) bool{
+	return shaderIsInWatchList(path)
+}
+
+// Returns the paths of all shader files in the watchlist, including #include'd files.
+func WatchedShaders() []string{
+	paths := make([]string, len(LoadedShaders))
+	for i, shaderFileInfo := range LoadedShaders {
+		paths[i] = shaderFileInfo.FilePath
+	}
+	return paths
+}
+
+// Removes the shader file from the watchlist, so it isn't checked for changes anymore (e.g. before
+// deleting it). Does nothing if it isn't watched. Note that building a program from the file again
+// (including a hotload of a program that uses it) puts it back on the watchlist.
+func Unwatch(path string){
+	for i, shaderFileInfo := range LoadedShaders {
+		if shaderFileInfo.FilePath == path {
+			LoadedShaders = append(LoadedShaders[:i], LoadedShaders[i+1:]...)
+			return
+		}
+	}
+}
+
+// Removes all shader files from the watchlist that aren't used by any program in LoadedPrograms.
+func removeUnusedShadersFromWatchList() {
+	usedShaders := []ShaderFileInfo{}
+	for _, shaderFileInfo := range LoadedShaders {
+		for _, program := range LoadedPrograms {
+			if programUsesShaderFile(program, shaderFileInfo.FilePath) {
+				usedShaders = append(usedShaders, shaderFileInfo)
+				break
+			}
+		}
+	}
+	LoadedShaders = usedShaders
+}
+
+// <toplevel function>
+// Reloads all textures in LoadedTextures whose file has changed, into their existing TextureID.
+// On error (e.g. the file is halfway written), the old texture stays in use and the error is logged.
+func HotloadTextures(){
+	for i := range LoadedTextures {
+		file, err := os.Stat(LoadedTextures[i].FilePath)
+		if err != nil {
+			// Don't complain every frame about a file that never existed yet
+			if !(LoadedTextures[i].Placeholder && os.IsNotExist(err)) {
+				log.Println(err)
+			}
+			continue
+		}
+		if file.ModTime().Equal(LoadedTextures[i].LastModified) {
+			continue
+		}
+		log.Printf("Texture %s has changed! \n", LoadedTextures[i].FilePath)
+		LoadedTextures[i].LastModified = file.ModTime()
+
+		pixels, dimensions, format, err := loadTexturePixels(LoadedTextures[i].FilePath, !LoadedTextures[i].Options.NoFlip)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		// Replace the image data, keeping the id (and thus all Sprites using it) the same
+		BindTexture(LoadedTextures[i].Texture)
+		texImage2D(pixels, dimensions, format, LoadedTextures[i].Options)
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+		textureSizes[LoadedTextures[i].Texture] = dimensions
+		textureFormats[LoadedTextures[i].Texture] = format
+
+		if LoadedTextures[i].Placeholder {
+			// From now on it's a regular texture, so drop the crisp filtering of the checkerboard
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+			LoadedTextures[i].Placeholder = false
+		}
+	}
+}
+
+// Adds the texture file to the texture watchlist, if it isn't in there already.
+func addTextureToWatchList(path string, texture TextureID, options TextureOptions) {
+	for _, textureFileInfo := range LoadedTextures {
+		if textureFileInfo.Texture == texture {
+			return
+		}
+	}
+	file, err := os.Stat(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	LoadedTextures = append(LoadedTextures, TextureFileInfo{
+		FilePath: path,
+		LastModified: file.ModTime(),
+		Texture: texture,
+		Options: options,
+	})
+}
+
+// Adds the path of a texture that failed to load to the texture watchlist, with the missing texture
+// as its texture. The file may not exist (yet): then it is loaded as soon as it is created.
+func addPlaceholderTextureToWatchList(path string, texture TextureID, options TextureOptions) {
+	// A zero time differs from any modification time, so an existing (but broken) file is only
+	// reloaded after it changes, and a missing one as soon as it shows up
+	lastModified := time.Time{}
+	if file, err := os.Stat(path); err == nil {
+		lastModified = file.ModTime()
+	}
+	LoadedTextures = append(LoadedTextures, TextureFileInfo{
+		FilePath: path,
+		LastModified: lastModified,
+		Texture: texture,
+		Options: options,
+		Placeholder: true,
+	})
+}
+
+// Removes the texture from the texture watchlist, if it is in there.
+func removeTextureFromWatchList(texture TextureID) {
+	for i, textureFileInfo := range LoadedTextures {
+		if textureFileInfo.Texture == texture {
+			LoadedTextures = append(LoadedTextures[:i], LoadedTextures[i+1:]...)
+			return
+		}
+	}
+}
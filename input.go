@@ -0,0 +1,144 @@
+package gogl
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+/*
+	INPUT
+
+	Input wraps a glfw Window and keeps track of the keyboard and mouse state
+	from frame to frame, so that we can ask not only whether a key is down, but
+	also whether it was pressed or released this frame.
+
+	Call input.PollInput() once per frame, right after glfw.PollEvents().
+	Controllers are tracked as well, see gamepad.go.
+*/
+
+type Input struct {
+	Window      *glfw.Window                    // The window we read the input from
+	keys        [glfw.KeyLast + 1]bool          // Key state of the current frame
+	prevKeys    [glfw.KeyLast + 1]bool          // Key state of the previous frame
+	buttons     [glfw.MouseButtonLast + 1]bool  // Mouse button state of the current frame
+	prevButtons [glfw.MouseButtonLast + 1]bool  // Mouse button state of the previous frame
+	CursorX     float64                         // X position of the cursor in screen coordinates (pixels, from the left)
+	CursorY     float64                         // Y position of the cursor in screen coordinates (pixels, from the top)
+	CursorXn    float32                         // X position of the cursor in normalized device coordinates (-1 left, 1 right)
+	CursorYn    float32                         // Y position of the cursor in normalized device coordinates (-1 bottom, 1 top)
+	gamepads    [glfw.JoystickLast + 1]*Gamepad // State of every joystick glfw knows about
+}
+
+// Creates an Input that reads from the given window.
+func NewInput(window *glfw.Window) *Input {
+	input := &Input{Window: window}
+	for i := range input.gamepads {
+		input.gamepads[i] = NewGamepad(glfw.Joystick1 + glfw.Joystick(i))
+	}
+	input.PollInput()
+	return input
+}
+
+// Reads the current keyboard and mouse state from the window. Should be called once per
+// frame, after glfw.PollEvents(), so that the JustPressed/JustReleased checks work.
+func (input *Input) PollInput() {
+	// Move current state to previous state
+	input.prevKeys = input.keys
+	input.prevButtons = input.buttons
+
+	// Keys (GLFW keys start at KeySpace, lower values are invalid)
+	for key := glfw.KeySpace; key <= glfw.KeyLast; key++ {
+		input.keys[key] = input.Window.GetKey(key) == glfw.Press
+	}
+
+	// Mouse buttons
+	for button := glfw.MouseButton1; button <= glfw.MouseButtonLast; button++ {
+		input.buttons[button] = input.Window.GetMouseButton(button) == glfw.Press
+	}
+
+	// Cursor position, both in pixels and in normalized device coordinates
+	input.CursorX, input.CursorY = input.Window.GetCursorPos()
+	width, height := input.Window.GetSize()
+	if width > 0 && height > 0 {
+		input.CursorXn = float32(2*input.CursorX/float64(width) - 1)
+		input.CursorYn = float32(1 - 2*input.CursorY/float64(height))
+	}
+
+	// Controllers
+	for _, gamepad := range input.gamepads {
+		gamepad.Poll()
+	}
+}
+
+// Returns the gamepad with the given index (0 for the first controller that was plugged in, up to 15).
+// Check gamepad.Connected to see if it's actually there. Returns nil for an invalid index.
+func (input *Input) Gamepad(index int) *Gamepad {
+	if index < 0 || index >= len(input.gamepads) {
+		return nil
+	}
+	return input.gamepads[index]
+}
+
+// Returns true as long as the key is held down.
+func (input *Input) IsKeyDown(key glfw.Key) bool {
+	if key < 0 || key > glfw.KeyLast {
+		return false
+	}
+	return input.keys[key]
+}
+
+// Returns true only in the frame that the key went down.
+func (input *Input) IsKeyJustPressed(key glfw.Key) bool {
+	if key < 0 || key > glfw.KeyLast {
+		return false
+	}
+	return input.keys[key] && !input.prevKeys[key]
+}
+
+// Returns true only in the frame that the key was let go.
+func (input *Input) IsKeyJustReleased(key glfw.Key) bool {
+	if key < 0 || key > glfw.KeyLast {
+		return false
+	}
+	return !input.keys[key] && input.prevKeys[key]
+}
+
+// Returns true as long as the mouse button is held down.
+func (input *Input) IsMouseButtonDown(button glfw.MouseButton) bool {
+	if button < 0 || button > glfw.MouseButtonLast {
+		return false
+	}
+	return input.buttons[button]
+}
+
+// Returns true only in the frame that the mouse button went down.
+func (input *Input) IsMouseButtonJustPressed(button glfw.MouseButton) bool {
+	if button < 0 || button > glfw.MouseButtonLast {
+		return false
+	}
+	return input.buttons[button] && !input.prevButtons[button]
+}
+
+// Returns true only in the frame that the mouse button was let go.
+func (input *Input) IsMouseButtonJustReleased(button glfw.MouseButton) bool {
+	if button < 0 || button > glfw.MouseButtonLast {
+		return false
+	}
+	return !input.buttons[button] && input.prevButtons[button]
+}
+
+// Calls f for every key event: when a key is pressed, repeated while held (glfw.Repeat), or released.
+// Use this when every event matters (e.g. keyboard shortcuts in a text field), and IsKeyDown() and
+// friends for game controls. Replaces any key callback that was set on the window before.
+func OnKey(w *glfw.Window, f func(key glfw.Key, action glfw.Action, mods glfw.ModifierKey)) {
+	w.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		f(key, action, mods)
+	})
+}
+
+// Calls f for every character that is typed, with keyboard layout and modifiers (shift, dead keys)
+// applied. This is the one to use for text input. Replaces any char callback that was set on the window before.
+func OnChar(w *glfw.Window, f func(char rune)) {
+	w.SetCharCallback(func(w *glfw.Window, char rune) {
+		f(char)
+	})
+}
@@ -0,0 +1,35 @@
+package gogl
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// When true, Run() checks for changed shader files every frame (see HotloadShaders()).
+var HotloadInRun bool
+
+/*
+Runs the game loop until the window is closed. Every frame it polls the events, optionally
+hotloads changed shaders, calls frame with the seconds passed since the previous frame,
+and swaps the buffers. A minimal program looks like:
+
+	window := gogl.MustInit("title", 800, 600)
+	defer glfw.Terminate()
+	gogl.Run(window, func(dt float64) {
+		gogl.Clear()
+		// update and draw
+	})
+*/
+func Run(window *glfw.Window, frame func(dt float64)) {
+	clock := NewClock()
+	for !window.ShouldClose() {
+		glfw.PollEvents()
+
+		if HotloadInRun {
+			HotloadShaders()
+		}
+
+		frame(clock.Tick())
+
+		window.SwapBuffers()
+	}
+}
@@ -0,0 +1,141 @@
+package gogl
+
+import (
+	"math"
+)
+
+/*
+	MATH
+
+	Small amount of vector and matrix math, so that we don't have to pull in a full linear
+	algebra library for the few things gogl needs. It is tailored to sprite/2D use.
+
+	Matrices are stored column-major, which is the layout OpenGL expects, so they can be
+	uploaded directly: `m := gogl.Translate(x, y, 0); program.SetMat4("model", (*[16]float32)(&m))`.
+	Vectors are plain arrays, so they can be passed to e.g. Program.SetFloatVector2() as well.
+*/
+
+type Vec2 [2]float32
+type Vec3 [3]float32
+type Vec4 [4]float32
+type Mat4 [16]float32
+
+// ------------------------------------------------------------------------------------------
+// [ Vectors ]
+
+func (v Vec2) Add(other Vec2) Vec2     { return Vec2{v[0] + other[0], v[1] + other[1]} }
+func (v Vec2) Sub(other Vec2) Vec2     { return Vec2{v[0] - other[0], v[1] - other[1]} }
+func (v Vec2) Mul(scalar float32) Vec2 { return Vec2{v[0] * scalar, v[1] * scalar} }
+func (v Vec2) Len() float32            { return float32(math.Hypot(float64(v[0]), float64(v[1]))) }
+
+func (v Vec3) Add(other Vec3) Vec3 {
+	return Vec3{v[0] + other[0], v[1] + other[1], v[2] + other[2]}
+}
+func (v Vec3) Sub(other Vec3) Vec3 {
+	return Vec3{v[0] - other[0], v[1] - other[1], v[2] - other[2]}
+}
+func (v Vec3) Mul(scalar float32) Vec3 {
+	return Vec3{v[0] * scalar, v[1] * scalar, v[2] * scalar}
+}
+
+func (v Vec4) Add(other Vec4) Vec4 {
+	return Vec4{v[0] + other[0], v[1] + other[1], v[2] + other[2], v[3] + other[3]}
+}
+func (v Vec4) Sub(other Vec4) Vec4 {
+	return Vec4{v[0] - other[0], v[1] - other[1], v[2] - other[2], v[3] - other[3]}
+}
+func (v Vec4) Mul(scalar float32) Vec4 {
+	return Vec4{v[0] * scalar, v[1] * scalar, v[2] * scalar, v[3] * scalar}
+}
+
+// [/ Vectors ]
+// ------------------------------------------------------------------------------------------
+// [ Matrices ]
+
+// Returns the identity matrix (a matrix that changes nothing).
+func Identity() Mat4 {
+	return Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Returns a matrix that moves everything by (x, y, z).
+func Translate(x, y, z float32) Mat4 {
+	return Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		x, y, z, 1,
+	}
+}
+
+// Returns a matrix that scales everything by (x, y, z), around the origin.
+func Scale(x, y, z float32) Mat4 {
+	return Mat4{
+		x, 0, 0, 0,
+		0, y, 0, 0,
+		0, 0, z, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Returns a matrix that rotates everything counter-clockwise around the z axis (the
+// rotation you want for 2D), by the given angle in radians.
+func RotateZ(angle float32) Mat4 {
+	sin := float32(math.Sin(float64(angle)))
+	cos := float32(math.Cos(float64(angle)))
+	return Mat4{
+		cos, sin, 0, 0,
+		-sin, cos, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Returns an orthographic projection matrix that maps the given box onto the screen.
+func Ortho(left, right, bottom, top, near, far float32) Mat4 {
+	return Mat4{
+		2 / (right - left), 0, 0, 0,
+		0, 2 / (top - bottom), 0, 0,
+		0, 0, -2 / (far - near), 0,
+		-(right + left) / (right - left), -(top + bottom) / (top - bottom), -(far + near) / (far - near), 1,
+	}
+}
+
+// Returns an orthographic projection matrix that maps the given rectangle onto the screen.
+// E.g.: Ortho2D(0, 800, 0, 600) lets you work in pixel coordinates on a 800x600 window,
+// with the origin at the bottom-left. Depth runs from -1 (near) to 1 (far).
+func Ortho2D(left, right, bottom, top float32) [16]float32 {
+	return [16]float32(Ortho(left, right, bottom, top, -1, 1))
+}
+
+// Returns m * other. Applied to a vector, other is applied first, then m.
+// E.g.: Translate(x, y, 0).Mul(RotateZ(a)) rotates first, then moves.
+func (m Mat4) Mul(other Mat4) Mat4 {
+	var result Mat4
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			var sum float32
+			for k := 0; k < 4; k++ {
+				sum += m[k*4+row] * other[col*4+k]
+			}
+			result[col*4+row] = sum
+		}
+	}
+	return result
+}
+
+// Returns m * v.
+func (m Mat4) MulVec4(v Vec4) Vec4 {
+	var result Vec4
+	for row := 0; row < 4; row++ {
+		result[row] = m[row]*v[0] + m[4+row]*v[1] + m[8+row]*v[2] + m[12+row]*v[3]
+	}
+	return result
+}
+
+// [/ Matrices ]
+// ------------------------------------------------------------------------------------------
@@ -0,0 +1,171 @@
+package gogl
+
+/*
+	OBJ
+
+	Loads a Wavefront .obj model into a GOGL_MESH DataObject. Supported are
+	positions (v), texture coordinates (vt), normals (vn) and faces (f) in all
+	index forms (v, v/vt, v//vn, v/vt/vn, also negative indices). Faces with more
+	than three corners are split into triangles. Materials, groups and smoothing
+	groups are ignored.
+
+	Usage:
+		model, err := gogl.LoadOBJ("assets/model.obj")
+		model.ProgramName = "model"
+		model.VertexShaderSource = "shaders/model.vert"
+		model.FragmentShaderSource = "shaders/model.frag"
+		model.ProcessData()
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Reads the .obj file, and returns a DataObject with its Vertices and Indices filled in, in the
+// GOGL_MESH layout. Corners without a texture coordinate or normal get zeroes for those.
+// Fill in the program fields, and call ProcessData() to get it ready for drawing.
+func LoadOBJ(path string) (*DataObject, error) {
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := [][3]float32{}
+	texcoords := [][2]float32{}
+	normals := [][3]float32{}
+
+	data := &DataObject{Type: GOGL_MESH}
+
+	// Every distinct position/texcoord/normal combination becomes one vertex
+	vertexIndices := make(map[[3]int]uint32)
+
+	for lineIndex, line := range strings.Split(string(fileData), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			values, err := parseOBJFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineIndex+1, err)
+			}
+			positions = append(positions, [3]float32{values[0], values[1], values[2]})
+
+		case "vt":
+			values, err := parseOBJFloats(fields[1:], 2)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineIndex+1, err)
+			}
+			texcoords = append(texcoords, [2]float32{values[0], values[1]})
+
+		case "vn":
+			values, err := parseOBJFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineIndex+1, err)
+			}
+			normals = append(normals, [3]float32{values[0], values[1], values[2]})
+
+		case "f":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("%s:%d: face needs at least 3 corners", path, lineIndex+1)
+			}
+			corners := make([]uint32, len(fields)-1)
+			for i, corner := range fields[1:] {
+				key, err := parseOBJCorner(corner, len(positions), len(texcoords), len(normals))
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: %w", path, lineIndex+1, err)
+				}
+
+				index, ok := vertexIndices[key]
+				if !ok {
+					index = uint32(len(data.Vertices) / 8)
+					vertexIndices[key] = index
+
+					position := positions[key[0]]
+					var texcoord [2]float32
+					if key[1] >= 0 {
+						texcoord = texcoords[key[1]]
+					}
+					var normal [3]float32
+					if key[2] >= 0 {
+						normal = normals[key[2]]
+					}
+					data.Vertices = append(data.Vertices,
+						position[0], position[1], position[2],
+						normal[0], normal[1], normal[2],
+						texcoord[0], texcoord[1],
+					)
+				}
+				corners[i] = index
+			}
+
+			// Split the face into a fan of triangles
+			for i := 1; i+1 < len(corners); i++ {
+				data.Indices = append(data.Indices, corners[0], corners[i], corners[i+1])
+			}
+		}
+	}
+
+	if len(data.Indices) == 0 {
+		return nil, fmt.Errorf("%s: no faces found", path)
+	}
+
+	return data, nil
+}
+
+// Parses at least count floats from the fields, extra fields (like the optional w) are ignored.
+func parseOBJFloats(fields []string, count int) ([]float32, error) {
+	if len(fields) < count {
+		return nil, fmt.Errorf("expected %d values, got %d", count, len(fields))
+	}
+	values := make([]float32, count)
+	for i := range values {
+		value, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = float32(value)
+	}
+	return values, nil
+}
+
+// Parses a face corner (v, v/vt, v//vn or v/vt/vn) into 0-based position, texcoord and normal
+// indices. Missing texcoord and normal indices are -1.
+func parseOBJCorner(corner string, positionCount, texcoordCount, normalCount int) ([3]int, error) {
+	key := [3]int{-1, -1, -1}
+	counts := [3]int{positionCount, texcoordCount, normalCount}
+
+	parts := strings.Split(corner, "/")
+	if len(parts) > 3 {
+		return key, fmt.Errorf("invalid face corner %q", corner)
+	}
+	for i, part := range parts {
+		if part == "" {
+			if i == 0 {
+				return key, fmt.Errorf("face corner %q has no position", corner)
+			}
+			continue
+		}
+		index, err := strconv.Atoi(part)
+		if err != nil {
+			return key, fmt.Errorf("invalid face corner %q", corner)
+		}
+
+		// OBJ indices start at 1, negative indices count back from the last one read
+		if index < 0 {
+			index = counts[i] + index
+		} else {
+			index--
+		}
+		if index < 0 || index >= counts[i] {
+			return key, fmt.Errorf("face corner %q refers to a value that doesn't exist (yet)", corner)
+		}
+		key[i] = index
+	}
+	return key, nil
+}
@@ -0,0 +1,152 @@
+package gogl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseOBJCorner(t *testing.T) {
+	// 4 positions, 2 texcoords and 3 normals read so far
+	tests := []struct {
+		corner  string
+		want    [3]int
+		wantErr bool
+	}{
+		{corner: "1", want: [3]int{0, -1, -1}},
+		{corner: "4", want: [3]int{3, -1, -1}},
+		{corner: "2/1", want: [3]int{1, 0, -1}},
+		{corner: "2//3", want: [3]int{1, -1, 2}},
+		{corner: "3/2/1", want: [3]int{2, 1, 0}},
+		{corner: "-1", want: [3]int{3, -1, -1}},
+		{corner: "-4/-2/-3", want: [3]int{0, 0, 0}},
+		{corner: "0", wantErr: true},
+		{corner: "5", wantErr: true},
+		{corner: "-5", wantErr: true},
+		{corner: "1/3", wantErr: true},
+		{corner: "1//4", wantErr: true},
+		{corner: "/1/1", wantErr: true},
+		{corner: "1/1/1/1", wantErr: true},
+		{corner: "a", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseOBJCorner(test.corner, 4, 2, 3)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseOBJCorner(%q): got %v, want an error", test.corner, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseOBJCorner(%q): %v", test.corner, err)
+		} else if got != test.want {
+			t.Errorf("parseOBJCorner(%q): got %v, want %v", test.corner, got, test.want)
+		}
+	}
+}
+
+// Writes the .obj source to a temporary file and loads it.
+func loadTestOBJ(t *testing.T, source string) (*DataObject, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.obj")
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadOBJ(path)
+}
+
+func TestLoadOBJ(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       string
+		wantVertices []float32
+		wantIndices  []uint32
+	}{
+		{
+			name: "triangle with positions only",
+			source: `# comment
+v 0 0 0
+v 1 0 0
+v 0 1 0 1.0
+f 1 2 3
+`,
+			wantVertices: []float32{
+				0, 0, 0, 0, 0, 0, 0, 0,
+				1, 0, 0, 0, 0, 0, 0, 0,
+				0, 1, 0, 0, 0, 0, 0, 0,
+			},
+			wantIndices: []uint32{0, 1, 2},
+		},
+		{
+			name: "quad is split into a fan, shared corners become one vertex",
+			source: `v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1 1
+vn 0 0 1
+f 1/1/1 2/1/1 3/2/1 4/2/1
+`,
+			wantVertices: []float32{
+				0, 0, 0, 0, 0, 1, 0, 0,
+				1, 0, 0, 0, 0, 1, 0, 0,
+				1, 1, 0, 0, 0, 1, 1, 1,
+				0, 1, 0, 0, 0, 1, 1, 1,
+			},
+			wantIndices: []uint32{0, 1, 2, 0, 2, 3},
+		},
+		{
+			name: "relative indices and v//vn",
+			source: `v 0 0 0
+v 1 0 0
+v 0 1 0
+vn 0 1 0
+f -3//-1 -2//-1 -1//-1
+f 1//1 2//1 3//1
+`,
+			wantVertices: []float32{
+				0, 0, 0, 0, 1, 0, 0, 0,
+				1, 0, 0, 0, 1, 0, 0, 0,
+				0, 1, 0, 0, 1, 0, 0, 0,
+			},
+			wantIndices: []uint32{0, 1, 2, 0, 1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := loadTestOBJ(t, test.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Type != GOGL_MESH {
+				t.Errorf("Type is %d, want GOGL_MESH", data.Type)
+			}
+			if !reflect.DeepEqual(data.Vertices, test.wantVertices) {
+				t.Errorf("Vertices\n got %v\nwant %v", data.Vertices, test.wantVertices)
+			}
+			if !reflect.DeepEqual(data.Indices, test.wantIndices) {
+				t.Errorf("Indices got %v, want %v", data.Indices, test.wantIndices)
+			}
+		})
+	}
+}
+
+func TestLoadOBJErrors(t *testing.T) {
+	tests := map[string]string{
+		"no faces":             "v 0 0 0\nv 1 0 0\nv 0 1 0\n",
+		"face with 2 corners":  "v 0 0 0\nv 1 0 0\nf 1 2\n",
+		"index out of range":   "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n",
+		"face before vertices": "f 1 2 3\nv 0 0 0\nv 1 0 0\nv 0 1 0\n",
+		"bad number":           "v 0 x 0\n",
+		"too few values":       "v 0 0\n",
+	}
+	for name, source := range tests {
+		if _, err := loadTestOBJ(t, source); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
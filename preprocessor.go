@@ -0,0 +1,90 @@
+package gogl
+
+/*
+	PREPROCESSOR
+
+	GLSL has no #include, so we resolve it ourselves before handing the source to GL.
+	A line like `#include "lighting.glsl"` is replaced by the contents of that file.
+	The path is relative to the file that includes it. Included files can include other
+	files, but not (indirectly) themselves.
+
+	Included files are added to the hotloading watchlist as well, and ShaderIncludes
+	remembers which shader includes what, so that editing a shared snippet rebuilds
+	every program that uses it.
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Maps a shader file path to all the files it includes (directly or indirectly).
+var ShaderIncludes = make(map[string][]string)
+
+// Reads the shader file and resolves its #include directives.
+// Returns the resulting source, and the paths of all included files.
+func preprocessShaderFile(path string) (string, []string, error) {
+	includes := []string{}
+	source, err := resolveIncludes(path, map[string]bool{}, &includes)
+	if err != nil {
+		return "", nil, err
+	}
+	return source, includes, nil
+}
+
+// Returns the source of the file at path with all includes inlined. includeStack holds the
+// files we are currently in the middle of, to detect circular includes.
+func resolveIncludes(path string, includeStack map[string]bool, includes *[]string) (string, error) {
+	if includeStack[path] {
+		return "", fmt.Errorf("circular #include of %s", path)
+	}
+	includeStack[path] = true
+	defer delete(includeStack, path)
+
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var source strings.Builder
+	for lineNumber, line := range strings.SplitAfter(string(fileData), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#include") {
+			source.WriteString(line)
+			continue
+		}
+
+		// Get the path between the quotes
+		argument := strings.TrimSpace(strings.TrimPrefix(trimmed, "#include"))
+		if len(argument) < 2 || argument[0] != '"' || argument[len(argument)-1] != '"' {
+			return "", fmt.Errorf("%s:%d: expected #include \"path\", got %s", path, lineNumber+1, trimmed)
+		}
+		includePath := filepath.Join(filepath.Dir(path), argument[1:len(argument)-1])
+
+		includedSource, err := resolveIncludes(includePath, includeStack, includes)
+		if err != nil {
+			return "", fmt.Errorf("%s:%d: %w", path, lineNumber+1, err)
+		}
+		source.WriteString(includedSource)
+		if !strings.HasSuffix(includedSource, "\n") {
+			source.WriteString("\n")
+		}
+
+		if !containsString(*includes, includePath) {
+			*includes = append(*includes, includePath)
+		}
+	}
+
+	return source.String(), nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
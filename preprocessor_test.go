@@ -0,0 +1,80 @@
+package gogl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Writes the files (path relative to a temporary directory -> contents), and returns the directory.
+func writeShaderFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPreprocessShaderFileRelativeIncludes(t *testing.T) {
+	dir := writeShaderFiles(t, map[string]string{
+		"shaders/main.frag":         "#version 450 core\n#include \"lib/lighting.glsl\"\n  #include \"lib/shapes.glsl\"\nvoid main() {}\n",
+		"shaders/lib/lighting.glsl": "#include \"../common/util.glsl\"\nlighting",
+		"shaders/lib/shapes.glsl":   "#include \"../common/util.glsl\"\nshapes\n",
+		"shaders/common/util.glsl":  "util\n",
+	})
+
+	source, includes, err := preprocessShaderFile(filepath.Join(dir, "shaders/main.frag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// util is included twice (not circular), and a missing newline at the end of an include is added
+	wantSource := "#version 450 core\nutil\nlighting\nutil\nshapes\nvoid main() {}\n"
+	if source != wantSource {
+		t.Errorf("source is\n%q\nwant\n%q", source, wantSource)
+	}
+
+	wantIncludes := []string{
+		filepath.Join(dir, "shaders/common/util.glsl"),
+		filepath.Join(dir, "shaders/lib/lighting.glsl"),
+		filepath.Join(dir, "shaders/lib/shapes.glsl"),
+	}
+	if !reflect.DeepEqual(includes, wantIncludes) {
+		t.Errorf("includes are\n%v\nwant\n%v", includes, wantIncludes)
+	}
+}
+
+func TestPreprocessShaderFileCircularInclude(t *testing.T) {
+	dir := writeShaderFiles(t, map[string]string{
+		"a.glsl": "#include \"b.glsl\"\n",
+		"b.glsl": "#include \"sub/../a.glsl\"\n",
+	})
+
+	_, _, err := preprocessShaderFile(filepath.Join(dir, "a.glsl"))
+	if err == nil || !strings.Contains(err.Error(), "circular #include") {
+		t.Errorf("got error %v, want a circular #include error", err)
+	}
+}
+
+func TestPreprocessShaderFileErrors(t *testing.T) {
+	dir := writeShaderFiles(t, map[string]string{
+		"missing.frag":  "#include \"nothere.glsl\"\n",
+		"unquoted.frag": "void main() {}\n#include nothere.glsl\n",
+	})
+
+	for _, name := range []string{"missing.frag", "unquoted.frag", "doesnotexist.frag"} {
+		if _, _, err := preprocessShaderFile(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
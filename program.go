@@ -1,78 +1,211 @@
 package gogl
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/go-gl/gl/v4.5-core/gl"
 )
 
+// When true, the uniform setters log a warning the first time a uniform name can't be found in a program
+// (misspelled, or optimized away because the shader doesn't use it). Off by default.
+var WarnOnMissingUniforms bool
+
 type ProgramID uint32
 type Program struct {
 	ID                     ProgramID
 	ProgramName            string
 	VertexShaderFilePath   string
 	FragmentShaderFilePath string
+	CombinedShaderFile     bool             // True when both stages come from one file (see MakeProgramFromCombinedFile)
+	missingUniforms        map[string]bool  // Uniform names we already warned about (see WarnOnMissingUniforms)
+	uniformLocations       map[string]int32 // Locations looked up so far, for the program in uniformLocationsID
+	uniformLocationsID     ProgramID        // The id uniformLocations belongs to, a rebuilt program starts over
 }
 
-// Loads the given value as a Uniform1f uniform to be consumed by a shader
-func (program *Program) SetFloat(name string, value float32) {
+// Makes this the program that is used for drawing. Does nothing if it already is.
+func (program *Program) Use() {
+	UseProgram(program.ID)
+}
+
+// Returns the location of the uniform with the given name, or -1 if the program doesn't have it
+// (also when it was optimized away because the shader doesn't use it).
+// Locations are cached, so only the first lookup of a name asks GL.
+func (program *Program) GetUniformLocation(name string) int32 {
+	// The cache is only valid for the current build of the program (hotloading changes the id)
+	if program.uniformLocations == nil || program.uniformLocationsID != program.ID {
+		program.uniformLocations = make(map[string]int32)
+		program.uniformLocationsID = program.ID
+	}
+	if location, ok := program.uniformLocations[name]; ok {
+		return location
+	}
+
 	name_cstr := gl.Str(name + "\x00")
 	location := gl.GetUniformLocation(uint32(program.ID), name_cstr)
+	program.uniformLocations[name] = location
+
+	if location == -1 && WarnOnMissingUniforms && !program.missingUniforms[name] {
+		if program.missingUniforms == nil {
+			program.missingUniforms = make(map[string]bool)
+		}
+		program.missingUniforms[name] = true
+		log.Printf("Warning: uniform %s not found in program %s (%d), is it misspelled or unused? \n", name, program.ProgramName, program.ID)
+	}
+
+	return location
+}
+
+// Returns the location of the vertex attribute with the given name, or -1 if the program doesn't have it.
+func (program *Program) GetAttribLocation(name string) int32 {
+	name_cstr := gl.Str(name + "\x00")
+	return gl.GetAttribLocation(uint32(program.ID), name_cstr)
+}
+
+// Loads the given value as a Uniform1f uniform to be consumed by a shader
+func (program *Program) SetFloat(name string, value float32) {
+	location := program.GetUniformLocation(name)
 	gl.Uniform1f(location, value)
 }
 
+// Loads all the given values as Uniform1f uniforms, e.g.:
+// program.SetUniforms(map[string]float32{"x": 0.5, "y": -0.2, "scale": 2})
+func (program *Program) SetUniforms(values map[string]float32) {
+	for name, value := range values {
+		gl.Uniform1f(program.GetUniformLocation(name), value)
+	}
+}
+
 // Loads the given value as a Uniform2fv uniform to be consumed by a shader
 func (program *Program) SetFloatVector2(name string, value *[2]float32) {
-	name_cstr := gl.Str(name + "\x00")
-	location := gl.GetUniformLocation(uint32(program.ID), name_cstr)
+	location := program.GetUniformLocation(name)
 	gl.Uniform2f(location, (*value)[0], (*value)[1])
 }
 
+// Loads the given value as a Uniform4f uniform to be consumed by a shader
+func (program *Program) SetFloatVector4(name string, value *[4]float32) {
+	location := program.GetUniformLocation(name)
+	gl.Uniform4f(location, (*value)[0], (*value)[1], (*value)[2], (*value)[3])
+}
+
+// Loads the given column-major 4x4 matrix as a UniformMatrix4fv uniform to be consumed by a shader
+func (program *Program) SetMat4(name string, value *[16]float32) {
+	location := program.GetUniformLocation(name)
+	gl.UniformMatrix4fv(location, 1, false, &(*value)[0])
+}
+
 // Loads the given value as a Uniform1f uniform to be consumed by a shader
 func (program *Program) SetInt(name string, value int32) {
-	name_cstr := gl.Str(name + "\x00")
-	location := gl.GetUniformLocation(uint32(program.ID), name_cstr)
+	location := program.GetUniformLocation(name)
 	gl.Uniform1i(location, value)
 }
 
+// Loads the given value as a Uniform1d uniform to be consumed by a shader (a `double` in GLSL).
+// Returns an error when the context doesn't support double precision in shaders (GL_ARB_gpu_shader_fp64,
+// core since GL 4.0).
+func (program *Program) SetDouble(name string, value float64) error {
+	if !supportsShaderFP64() {
+		return fmt.Errorf("program %s: can't set double uniform %s, GL_ARB_gpu_shader_fp64 is not supported", program.ProgramName, name)
+	}
+	location := program.GetUniformLocation(name)
+	gl.Uniform1d(location, value)
+	return nil
+}
+
+// Returns true when shaders can use doubles: GL 4.0 and up, or the GL_ARB_gpu_shader_fp64 extension.
+func supportsShaderFP64() bool {
+	var majorVersion int32
+	gl.GetIntegerv(gl.MAJOR_VERSION, &majorVersion)
+	return majorVersion >= 4 || HasExtension("GL_ARB_gpu_shader_fp64")
+}
+
+// Binds the texture to the given texture unit, and points the sampler uniform with the given name to that unit.
+// Use a different unit for every texture the shader samples from (e.g. 0 for diffuse, 1 for the normal map).
+func (program *Program) SetTexture(name string, unit uint32, tex TextureID) {
+	BindTextureUnit(tex, unit)
+	program.SetInt(name, int32(unit))
+
+	// Go back to the default unit, so that a plain BindTexture() still binds to unit 0
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
 /*
 Creates a Program, builds shaders, links shaders, and adds program
 to custom watchlist "LoadedPrograms", which allows us to use ReloadProgram()
 when one of the shaderfiles get modified.
+Compile and link errors are returned, in which case nothing is added to LoadedPrograms.
 */
 func MakeProgram(programName string, vertexShaderPath string, fragmentShaderPath string) (*Program, error) {
-	// Create shaders
-	vertexShaderID, err := LoadShader(vertexShaderPath, gl.VERTEX_SHADER)
-	if err != nil {
-		return nil, err
-	}
-	fragmentShaderID, err2 := LoadShader(fragmentShaderPath, gl.FRAGMENT_SHADER)
-	if err2 != nil {
-		return nil, err2
+	// Try the program binary cache first (only when ProgramBinaryCacheDir is set)
+	sourceHash := ""
+	programID := ProgramID(0)
+	if ProgramBinaryCacheDir != "" {
+		hash, err := programSourceHash(vertexShaderPath, fragmentShaderPath)
+		if err != nil {
+			return nil, err
+		}
+		sourceHash = hash
+		programID = loadProgramBinary(sourceHash)
 	}
 
-	// Create program & link shaders
-	programID := ProgramID(gl.CreateProgram())
-	AttachShader(programID, vertexShaderID)
-	AttachShader(programID, fragmentShaderID)
-	LinkProgram(programID)
+	if programID != 0 {
+		// Cache hit, the shaders weren't compiled, so register them for hotloading here
+		addShaderToWatchList(vertexShaderPath)
+		addShaderToWatchList(fragmentShaderPath)
+	} else {
+		// Create shaders
+		vertexShaderID, err := LoadShader(vertexShaderPath, gl.VERTEX_SHADER)
+		if err != nil {
+			return nil, err
+		}
+		fragmentShaderID, err2 := LoadShader(fragmentShaderPath, gl.FRAGMENT_SHADER)
+		if err2 != nil {
+			gl.DeleteShader(uint32(vertexShaderID))
+			return nil, err2
+		}
 
-	// Log error and stop execution if failed
-	err = CheckProgramLinkSuccess(programID)
-	if err != nil {
-		panic(err)
+		// Create program & link shaders
+		programID = ProgramID(gl.CreateProgram())
+		if sourceHash != "" {
+			gl.ProgramParameteri(uint32(programID), gl.PROGRAM_BINARY_RETRIEVABLE_HINT, gl.TRUE)
+		}
+		AttachShader(programID, vertexShaderID)
+		AttachShader(programID, fragmentShaderID)
+		LinkProgram(programID)
+
+		// After linking, we can delete the shaders
+		gl.DeleteShader(uint32(vertexShaderID))
+		gl.DeleteShader(uint32(fragmentShaderID))
+
+		// Return the error if linking failed, and let the caller decide what to do with it
+		err = CheckProgramLinkSuccess(programID)
+		if err != nil {
+			gl.DeleteProgram(uint32(programID))
+			return nil, err
+		}
+
+		// Store the binary, so we can skip compilation next time
+		if sourceHash != "" {
+			if err := saveProgramBinary(programID, sourceHash); err != nil {
+				log.Printf("Could not cache program %s: %s \n", programName, err)
+			}
+		}
 	}
 
-	// After linking, we can delete the shaders
-	gl.DeleteShader(uint32(vertexShaderID))
-	gl.DeleteShader(uint32(fragmentShaderID))
+	return registerProgram(programName, programID, vertexShaderPath, fragmentShaderPath), nil
+}
 
-	// Keep track of the program in a watchlist, so we can update it when the shaders change
+// Keeps track of the program in a watchlist (LoadedPrograms), so we can update it when the shaders change.
+// If a program with the same name is already registered, only its id is updated.
+func registerProgram(programName string, programID ProgramID, vertexShaderPath string, fragmentShaderPath string) *Program {
 	programPtr, ok := LoadedPrograms[programName]
 	if ok == false {
 		// Add to the list
 		LoadedPrograms[programName] = &Program{
 			ID:                     programID,
+			ProgramName:            programName,
 			VertexShaderFilePath:   vertexShaderPath,
 			FragmentShaderFilePath: fragmentShaderPath,
 		}
@@ -83,5 +216,272 @@ func MakeProgram(programName string, vertexShaderPath string, fragmentShaderPath
 
 	log.Printf("Program %s (%d) compiled succesfully. \n", programName, programID)
 
-	return LoadedPrograms[programName], nil
+	return LoadedPrograms[programName]
+}
+
+/*
+Same as MakeProgram(), but reads both stages from a single file, where the stages are marked
+with `#shader vertex` and `#shader fragment` lines:
+
+	#shader vertex
+	#version 450 core
+	...
+	#shader fragment
+	#version 450 core
+	...
+
+The file is added to the hotloading watchlist, and a change to it rebuilds both stages.
+*/
+func MakeProgramFromCombinedFile(programName string, path string) (*Program, error) {
+	// Read the file, and inline its #include's (see preprocessor.go)
+	combinedSource, includes, err := preprocessShaderFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vertexSource, fragmentSource, err := splitCombinedShader(combinedSource)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Create shaders
+	vertexShaderID, err := MakeShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return nil, withShaderFilePath(err, path)
+	}
+	fragmentShaderID, err := MakeShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		gl.DeleteShader(uint32(vertexShaderID))
+		return nil, withShaderFilePath(err, path)
+	}
+
+	// Create program & link shaders
+	programID := ProgramID(gl.CreateProgram())
+	AttachShader(programID, vertexShaderID)
+	AttachShader(programID, fragmentShaderID)
+	LinkProgram(programID)
+	gl.DeleteShader(uint32(vertexShaderID))
+	gl.DeleteShader(uint32(fragmentShaderID))
+
+	if err := CheckProgramLinkSuccess(programID); err != nil {
+		gl.DeleteProgram(uint32(programID))
+		return nil, withShaderFilePath(err, path)
+	}
+
+	addShaderToWatchList(path)
+	registerShaderIncludes(path, includes)
+	program := registerProgram(programName, programID, path, path)
+	program.CombinedShaderFile = true
+
+	return program, nil
+}
+
+/*
+Compiles, links and validates the given vertex and fragment shader source, and returns the first error
+found. Everything is deleted again afterwards, and nothing is added to LoadedPrograms or the hotloading
+watchlists, so this is cheap enough to call on every change in e.g. a shader editor.
+Note that validation checks the program against the current GL state (like bound textures).
+*/
+func TryCompileProgram(vertexShaderSource, fragmentShaderSource string) error {
+	vertexShaderID, err := MakeShader(vertexShaderSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return err
+	}
+	defer gl.DeleteShader(uint32(vertexShaderID))
+
+	fragmentShaderID, err := MakeShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return err
+	}
+	defer gl.DeleteShader(uint32(fragmentShaderID))
+
+	programID := ProgramID(gl.CreateProgram())
+	defer gl.DeleteProgram(uint32(programID))
+	AttachShader(programID, vertexShaderID)
+	AttachShader(programID, fragmentShaderID)
+	LinkProgram(programID)
+	if err := CheckProgramLinkSuccess(programID); err != nil {
+		return err
+	}
+
+	gl.ValidateProgram(uint32(programID))
+	var valid int32
+	gl.GetProgramiv(uint32(programID), gl.VALIDATE_STATUS, &valid)
+	if valid == gl.FALSE {
+		infoLog := programInfoLog(programID)
+		return &ShaderError{Stage: "validate", Log: infoLog, Entries: parseShaderLog(infoLog)}
+	}
+	return nil
+}
+
+// Returns the info log of the program, as filled in by linking or validating it.
+func programInfoLog(programID ProgramID) string {
+	var logLength int32
+	gl.GetProgramiv(uint32(programID), gl.INFO_LOG_LENGTH, &logLength)
+	if logLength == 0 {
+		return ""
+	}
+	infoLog := strings.Repeat("\x00", int(logLength+1))
+	gl.GetProgramInfoLog(uint32(programID), logLength, nil, gl.Str(infoLog))
+	return strings.TrimRight(infoLog, "\x00")
+}
+
+// Fills in the FilePath of a *ShaderError, and returns the error again. Other errors are returned as is.
+func withShaderFilePath(err error, path string) error {
+	var shaderErr *ShaderError
+	if errors.As(err, &shaderErr) {
+		shaderErr.FilePath = path
+	}
+	return err
+}
+
+// Splits the source of a combined shader file into the vertex and fragment source.
+// Every stage may only have one section.
+func splitCombinedShader(source string) (string, string, error) {
+	var vertexSource, fragmentSource strings.Builder
+	var current *strings.Builder
+	seenStages := make(map[string]bool)
+
+	for _, line := range strings.SplitAfter(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#shader") {
+			stage := strings.TrimSpace(strings.TrimPrefix(trimmed, "#shader"))
+			switch stage {
+			case "vertex":
+				current = &vertexSource
+			case "fragment":
+				current = &fragmentSource
+			default:
+				return "", "", fmt.Errorf("unknown shader stage in %q", trimmed)
+			}
+			if seenStages[stage] {
+				return "", "", fmt.Errorf("combined shader file has more than one `#shader %s` section", stage)
+			}
+			seenStages[stage] = true
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+		}
+	}
+
+	if vertexSource.Len() == 0 || fragmentSource.Len() == 0 {
+		return "", "", errors.New("combined shader file needs both a `#shader vertex` and a `#shader fragment` section")
+	}
+	return vertexSource.String(), fragmentSource.String(), nil
+}
+
+// Points the program to other shader files (e.g. another variant of a material), and rebuilds it.
+// Pass the same path twice for a combined shader file (see MakeProgramFromCombinedFile()).
+// On failure the program keeps its old shaders and compilation. Shader files that are no longer
+// used by any program are removed from the hotloading watchlist.
+// DataObjects that use the program look up the attribute locations of the new shaders in their next Enable().
+func (program *Program) SetShaders(vertexShaderPath, fragmentShaderPath string) error {
+	oldVertexShaderPath := program.VertexShaderFilePath
+	oldFragmentShaderPath := program.FragmentShaderFilePath
+	oldCombinedShaderFile := program.CombinedShaderFile
+
+	program.VertexShaderFilePath = vertexShaderPath
+	program.FragmentShaderFilePath = fragmentShaderPath
+	program.CombinedShaderFile = vertexShaderPath == fragmentShaderPath
+
+	err := rebuildProgram(program.ProgramName, program)
+	if err != nil {
+		program.VertexShaderFilePath = oldVertexShaderPath
+		program.FragmentShaderFilePath = oldFragmentShaderPath
+		program.CombinedShaderFile = oldCombinedShaderFile
+	}
+
+	// Also cleans up new files that were added to the watchlist before the build failed
+	removeUnusedShadersFromWatchList()
+	return err
+}
+
+// Deletes the program in GL and removes it from the LoadedPrograms watchlist.
+// Shader files that are no longer used by any of the remaining programs are removed
+// from the watchlist as well.
+func (program *Program) Delete() {
+	gl.DeleteProgram(uint32(program.ID))
+	program.ID = 0
+
+	// GL may hand out the same id to a new program, so don't trust the cached state anymore
+	ResetProgramState()
+
+	// Remove from watchlist (look up by pointer if the name doesn't match, to be safe)
+	if LoadedPrograms[program.ProgramName] == program {
+		delete(LoadedPrograms, program.ProgramName)
+	} else {
+		for name, loadedProgram := range LoadedPrograms {
+			if loadedProgram == program {
+				delete(LoadedPrograms, name)
+			}
+		}
+	}
+
+	removeUnusedShadersFromWatchList()
+}
+
+// Returns the uniforms that survived compilation, as "name type" (e.g. "tex_divisions float").
+// Uniforms that the shader declares but doesn't use are optimized away by the driver, and won't show up.
+func (program *Program) ListActiveUniforms() []string {
+	var count int32
+	gl.GetProgramiv(uint32(program.ID), gl.ACTIVE_UNIFORMS, &count)
+
+	var maxNameLength int32
+	gl.GetProgramiv(uint32(program.ID), gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxNameLength)
+
+	uniforms := []string{}
+	for i := uint32(0); i < uint32(count); i++ {
+		var length, size int32
+		var xtype uint32
+		name := make([]uint8, maxNameLength+1)
+		gl.GetActiveUniform(uint32(program.ID), i, maxNameLength+1, &length, &size, &xtype, &name[0])
+		uniforms = append(uniforms, string(name[:length])+" "+glslTypeName(xtype))
+	}
+	return uniforms
+}
+
+// Logs the active uniforms of the program, see ListActiveUniforms().
+func (program *Program) PrintActiveUniforms() {
+	log.Printf("Program %s (%d) active uniforms: %v \n", program.ProgramName, program.ID, program.ListActiveUniforms())
+}
+
+// Returns the GLSL name of a uniform type as returned by gl.GetActiveUniform.
+func glslTypeName(xtype uint32) string {
+	switch xtype {
+	case gl.FLOAT:
+		return "float"
+	case gl.FLOAT_VEC2:
+		return "vec2"
+	case gl.FLOAT_VEC3:
+		return "vec3"
+	case gl.FLOAT_VEC4:
+		return "vec4"
+	case gl.DOUBLE:
+		return "double"
+	case gl.INT:
+		return "int"
+	case gl.INT_VEC2:
+		return "ivec2"
+	case gl.INT_VEC3:
+		return "ivec3"
+	case gl.INT_VEC4:
+		return "ivec4"
+	case gl.UNSIGNED_INT:
+		return "uint"
+	case gl.BOOL:
+		return "bool"
+	case gl.FLOAT_MAT2:
+		return "mat2"
+	case gl.FLOAT_MAT3:
+		return "mat3"
+	case gl.FLOAT_MAT4:
+		return "mat4"
+	case gl.SAMPLER_2D:
+		return "sampler2D"
+	case gl.SAMPLER_2D_ARRAY:
+		return "sampler2DArray"
+	case gl.SAMPLER_CUBE:
+		return "samplerCube"
+	}
+	return fmt.Sprintf("type 0x%x", xtype)
 }
@@ -0,0 +1,72 @@
+package gogl
+
+import "testing"
+
+func TestSplitCombinedShader(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       string
+		wantVertex   string
+		wantFragment string
+		wantErr      bool
+	}{
+		{
+			name:         "both stages",
+			source:       "#shader vertex\n#version 450 core\nvoid main() {}\n#shader fragment\n#version 450 core\nout vec4 color;\n",
+			wantVertex:   "#version 450 core\nvoid main() {}\n",
+			wantFragment: "#version 450 core\nout vec4 color;\n",
+		},
+		{
+			name:         "fragment first, indented markers, text before the first marker is dropped",
+			source:       "// material\n  #shader fragment\nfrag\n\t#shader vertex  \nvert",
+			wantVertex:   "vert",
+			wantFragment: "frag\n",
+		},
+		{
+			name:    "missing fragment stage",
+			source:  "#shader vertex\nvert\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing vertex stage",
+			source:  "#shader fragment\nfrag\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty stage",
+			source:  "#shader vertex\nvert\n#shader fragment\n",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate stage",
+			source:  "#shader vertex\nvert\n#shader fragment\nfrag\n#shader vertex\nmore vert\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown stage",
+			source:  "#shader vertex\nvert\n#shader geometry\ngeom\n#shader fragment\nfrag\n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vertex, fragment, err := splitCombinedShader(test.source)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vertex != test.wantVertex {
+				t.Errorf("vertex source is %q, want %q", vertex, test.wantVertex)
+			}
+			if fragment != test.wantFragment {
+				t.Errorf("fragment source is %q, want %q", fragment, test.wantFragment)
+			}
+		})
+	}
+}
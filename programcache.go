@@ -0,0 +1,100 @@
+package gogl
+
+/*
+	PROGRAM BINARY CACHE
+
+	Compiling all shaders on every launch takes time. When ProgramBinaryCacheDir is set,
+	MakeProgram() stores the linked program binary in that directory, in a .progbin file
+	named after a hash of the shader sources. On the next launch the binary is loaded with
+	gl.ProgramBinary, and compilation is skipped.
+
+	When the sources change, the hash changes, so we compile again. When the driver rejects
+	a cached binary (e.g. after a driver update), we also just compile again.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Directory to store program binaries in. Caching is disabled when empty (the default).
+var ProgramBinaryCacheDir string
+
+// Returns a hash of the (preprocessed) sources of both shader files, so changes to included files count too.
+// The includes are registered as well, as the shaders aren't loaded through LoadShader() on a cache hit.
+func programSourceHash(vertexShaderPath string, fragmentShaderPath string) (string, error) {
+	vertexSource, vertexIncludes, err := preprocessShaderFile(vertexShaderPath)
+	if err != nil {
+		return "", err
+	}
+	fragmentSource, fragmentIncludes, err := preprocessShaderFile(fragmentShaderPath)
+	if err != nil {
+		return "", err
+	}
+	registerShaderIncludes(vertexShaderPath, vertexIncludes)
+	registerShaderIncludes(fragmentShaderPath, fragmentIncludes)
+
+	hash := sha256.New()
+	hash.Write([]byte(vertexSource))
+	hash.Write([]byte{0}) // separator, so moving code from one file to the other changes the hash
+	hash.Write([]byte(fragmentSource))
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func programBinaryCachePath(sourceHash string) string {
+	return filepath.Join(ProgramBinaryCacheDir, sourceHash+".progbin")
+}
+
+// Tries to create a program from a cached binary. Returns 0 on a cache miss, or when the
+// driver doesn't accept the binary.
+func loadProgramBinary(sourceHash string) ProgramID {
+	fileData, err := ioutil.ReadFile(programBinaryCachePath(sourceHash))
+	if err != nil || len(fileData) <= 4 {
+		return 0
+	}
+
+	// File layout: 4 bytes binary format, followed by the binary itself
+	format := binary.LittleEndian.Uint32(fileData[:4])
+	programBinary := fileData[4:]
+
+	programID := ProgramID(gl.CreateProgram())
+	gl.ProgramBinary(uint32(programID), format, gl.Ptr(programBinary), int32(len(programBinary)))
+
+	if err := CheckProgramLinkSuccess(programID); err != nil {
+		log.Println("Cached program binary was rejected, compiling instead")
+		gl.DeleteProgram(uint32(programID))
+		return 0
+	}
+
+	return programID
+}
+
+// Writes the binary of the given (linked) program to the cache directory.
+func saveProgramBinary(programID ProgramID, sourceHash string) error {
+	var length int32
+	gl.GetProgramiv(uint32(programID), gl.PROGRAM_BINARY_LENGTH, &length)
+	if length == 0 {
+		return errors.New("driver returned an empty program binary")
+	}
+
+	var format uint32
+	programBinary := make([]byte, length)
+	gl.GetProgramBinary(uint32(programID), length, nil, &format, gl.Ptr(programBinary))
+
+	fileData := make([]byte, 4+len(programBinary))
+	binary.LittleEndian.PutUint32(fileData[:4], format)
+	copy(fileData[4:], programBinary)
+
+	if err := os.MkdirAll(ProgramBinaryCacheDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(programBinaryCachePath(sourceHash), fileData, 0644)
+}
@@ -0,0 +1,67 @@
+package gogl
+
+import (
+	"image"
+	"image/png"
+	"os"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Reads the given area of the default framebuffer and saves it as a png file.
+// width and height should typically be the framebuffer size of the window.
+func SaveScreenshot(filename string, width, height int) error {
+	pixels := make([]byte, width*height*4)
+
+	// Make sure we read from the screen, and not from a bound Framebuffer
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	defer setPackAlignment(setPackAlignment(1))
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+
+	// GL starts at the bottom-left, images at the top-left
+	flipPixelRows(pixels, width*4)
+
+	// The alpha in the framebuffer is whatever blending left there, but the screen shows the colors
+	// as opaque, so the screenshot should be too
+	for i := 3; i < len(pixels); i += 4 {
+		pixels[i] = 255
+	}
+
+	img := &image.NRGBA{
+		Pix:    pixels,
+		Stride: width * 4,
+		Rect:   image.Rect(0, 0, width, height),
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return png.Encode(file, img)
+}
+
+// Returns the r, g, b, a color of one pixel of the framebuffer that is currently bound for reading
+// (the screen, or a bound Framebuffer), e.g. for picking objects by rendering their id as a color.
+// x, y is in pixels with the origin at the top-left of the viewport, like the cursor position
+// (multiply that by FramebufferScale() on HiDPI screens). Outside the framebuffer the result is undefined.
+func ReadPixel(x, y int) [4]byte {
+	// GL starts at the bottom-left, so flip y within the viewport
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	glX := viewport[0] + int32(x)
+	glY := viewport[1] + viewport[3] - 1 - int32(y)
+
+	var pixel [4]byte
+	defer setPackAlignment(setPackAlignment(1))
+	gl.ReadPixels(glX, glY, 1, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&pixel[0]))
+	return pixel
+}
+
+// Sets the row alignment gl.ReadPixels() uses, and returns the previous one, so it can be put back.
+func setPackAlignment(alignment int) int {
+	previous := getInteger(gl.PACK_ALIGNMENT)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, int32(alignment))
+	return previous
+}
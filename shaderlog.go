@@ -0,0 +1,64 @@
+package gogl
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+	SHADER LOG
+
+	Drivers all format their shader info logs a bit differently. This turns the
+	common formats into a list of entries with a line number, so tools can point
+	at the offending line. Line numbers refer to ShaderError.Source, which is the
+	source after #include's have been inlined.
+
+	Recognized formats:
+		0:12(3): error: ...           Mesa
+		0(12) : error C0000: ...      NVIDIA
+		ERROR: 0:12: ...              AMD, Intel, Apple (also WARNING:)
+*/
+
+// One message from a shader info log.
+type ShaderLogEntry struct {
+	Line    int    // Line in the source, starting at 1. 0 when the driver didn't give one (or the format wasn't recognized).
+	Column  int    // Column in the line, 0 when the driver didn't give one.
+	Message string // The message without the location, e.g. "error: `foo' undeclared". The raw line when not recognized.
+}
+
+var (
+	mesaLogLine   = regexp.MustCompile(`^\d+:(\d+)\((\d+)\):\s*(.*)$`)
+	nvidiaLogLine = regexp.MustCompile(`^\d+\((\d+)\)\s*:\s*(.*)$`)
+	prefixLogLine = regexp.MustCompile(`^(ERROR|WARNING):\s*\d+:(\d+):\s*(.*)$`)
+)
+
+// Splits the info log into entries, one per non-empty line.
+func parseShaderLog(infoLog string) []ShaderLogEntry {
+	entries := []ShaderLogEntry{}
+	for _, line := range strings.Split(infoLog, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		entries = append(entries, parseShaderLogLine(line))
+	}
+	return entries
+}
+
+func parseShaderLogLine(line string) ShaderLogEntry {
+	if match := mesaLogLine.FindStringSubmatch(line); match != nil {
+		lineNumber, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		return ShaderLogEntry{Line: lineNumber, Column: column, Message: match[3]}
+	}
+	if match := nvidiaLogLine.FindStringSubmatch(line); match != nil {
+		lineNumber, _ := strconv.Atoi(match[1])
+		return ShaderLogEntry{Line: lineNumber, Message: match[2]}
+	}
+	if match := prefixLogLine.FindStringSubmatch(line); match != nil {
+		lineNumber, _ := strconv.Atoi(match[2])
+		return ShaderLogEntry{Line: lineNumber, Message: strings.ToLower(match[1]) + ": " + match[3]}
+	}
+	return ShaderLogEntry{Message: line}
+}
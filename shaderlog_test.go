@@ -0,0 +1,73 @@
+package gogl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShaderLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want ShaderLogEntry
+	}{
+		{
+			name: "Mesa error",
+			line: "0:12(3): error: `foo' undeclared",
+			want: ShaderLogEntry{Line: 12, Column: 3, Message: "error: `foo' undeclared"},
+		},
+		{
+			name: "Mesa warning",
+			line: "0:4(10): warning: extension `GL_ARB_foo' unsupported in fragment shader",
+			want: ShaderLogEntry{Line: 4, Column: 10, Message: "warning: extension `GL_ARB_foo' unsupported in fragment shader"},
+		},
+		{
+			name: "NVIDIA error",
+			line: `0(7) : error C0000: syntax error, unexpected '}', expecting ',' or ';' at token "}"`,
+			want: ShaderLogEntry{Line: 7, Message: `error C0000: syntax error, unexpected '}', expecting ',' or ';' at token "}"`},
+		},
+		{
+			name: "NVIDIA warning",
+			line: "0(21) : warning C7050: \"color\" might be used before being initialized",
+			want: ShaderLogEntry{Line: 21, Message: "warning C7050: \"color\" might be used before being initialized"},
+		},
+		{
+			name: "Intel/AMD error",
+			line: "ERROR: 0:5: 'x' : undeclared identifier",
+			want: ShaderLogEntry{Line: 5, Message: "error: 'x' : undeclared identifier"},
+		},
+		{
+			name: "Intel/AMD warning",
+			line: "WARNING: 0:9: 'uv' : variable is not used",
+			want: ShaderLogEntry{Line: 9, Message: "warning: 'uv' : variable is not used"},
+		},
+		{
+			name: "unrecognized",
+			line: "ERROR: 2 compilation errors.  No code generated.",
+			want: ShaderLogEntry{Message: "ERROR: 2 compilation errors.  No code generated."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseShaderLogLine(test.line); got != test.want {
+				t.Errorf("parseShaderLogLine(%q)\n got %+v\nwant %+v", test.line, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseShaderLog(t *testing.T) {
+	infoLog := "ERROR: 0:5: 'x' : undeclared identifier\r\n\n  ERROR: 1 compilation errors.  No code generated.\n"
+	want := []ShaderLogEntry{
+		{Line: 5, Message: "error: 'x' : undeclared identifier"},
+		{Message: "ERROR: 1 compilation errors.  No code generated."},
+	}
+	if got := parseShaderLog(infoLog); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if got := parseShaderLog(""); len(got) != 0 {
+		t.Errorf("empty log: got %+v, want no entries", got)
+	}
+}
@@ -1,42 +1,123 @@
 package gogl
 
-import (
-	"github.com/go-gl/gl/v4.5-core/gl"
-)
-
 type Sprite struct {
-	Name            string      // Descriptive name, might be used in debug logging.
-	TextureSource   string      // The filepath of the image that will be loaded in as a texture. Can be a relative path. Texture is loaded in AddSprite().
-	Divisions       int         // How many tiles the spritesheet is divided up in
-	Texture         TextureID   // ID of the texture that serves as the spritesheet
-	AnimationFrames [][]float32 // In which part of the sprite sheet is each animation frame located?
-	AnimationSpeed  int         // How many ticks does it take to advance a frame?
-	TickCount       int         // Keeps track of the game loops that have passed. Is reset to 0 when TickCount==AnimationSpeed
-	CurrentFrame    int         // Index of a frame in sprite.AnimationFrames
-	Xn              float32     // X location of sprite tile on the screen (normalized values)
-	Yn              float32     // Y location of sprite tile on the screen (normalized values)
-	Scale           float32     // Weird way to scale up/down the sprite :)
-	FlipHorizontal  float32     // 1.0 for flip horizontal, 0.0 for no flip
+	Name            string            // Descriptive name, might be used in debug logging.
+	TextureSource   string            // The filepath of the image that will be loaded in as a texture. Can be a relative path. Texture is loaded in AddSprite().
+	Divisions       int               // How many tiles the spritesheet is divided up in
+	Texture         TextureID         // ID of the texture that serves as the spritesheet
+	AnimationFrames [][]float32       // In which part of the sprite sheet is each animation frame located? {tex_x, tex_y}, or {u0, v0, u1, v1} when UseAtlasRects is set
+	UseAtlasRects   bool              // When true, AnimationFrames hold explicit texture rectangles instead of grid positions, and Divisions is ignored
+	AnimationSpeed  int               // How many ticks does it take to advance a frame?
+	TickCount       int               // Keeps track of the game loops that have passed. Is reset to 0 when TickCount==AnimationSpeed
+	CurrentFrame    int               // Index of a frame in sprite.AnimationFrames
+	Xn              float32           // X location of sprite tile on the screen (normalized values)
+	Yn              float32           // Y location of sprite tile on the screen (normalized values)
+	Z               float32           // Depth of the sprite, -1 (front) .. 1 (back). Only affects layering with EnableDepthTest(), see SetUniforms().
+	Scale           float32           // Weird way to scale up/down the sprite :)
+	FlipHorizontal  float32           // 1.0 for flip horizontal, 0.0 for no flip
+	AnchorX         float32           // Pivot point for positioning, rotating and scaling, 0 (left) .. 1 (right). See AddSprite() for the default.
+	AnchorY         float32           // Pivot point for positioning, rotating and scaling, 0 (bottom) .. 1 (top). See AddSprite() for the default.
+	ExtraTextures   []SpriteTexture   // Optional extra textures (e.g. a normal map), bound to texture unit 1, 2, ... in order.
+	FrameDurations  []int             // Optional duration of each animation frame in milliseconds (filled in by LoadSpriteFromJSON)
+	AnimationTags   map[string][2]int // Optional named animations, as [from, to] indices into AnimationFrames (filled in by LoadSpriteFromJSON)
+}
+
+// An extra texture for a Sprite, next to the main spritesheet Texture (which is always on unit 0).
+type SpriteTexture struct {
+	Uniform       string    // Name of the sampler uniform in the shader, e.g. "normal_map"
+	TextureSource string    // The filepath of the image. Texture is loaded in AddSprite().
+	Texture       TextureID // ID of the loaded texture
 }
 
 // Initializes and adds Sprite to the DataObject for later use.
-// Also loads Texture from source, if it wasn't already loaded.
+// Also loads Texture from source, if it wasn't already loaded. When TextureSource is empty,
+// the Texture that is set on the Sprite is used as is.
+// When AnchorX and AnchorY are both 0, the anchor is set to the center (0.5, 0.5). To anchor at
+// exactly the bottom-left corner, set the anchor on data.Sprites[i] after adding it.
 func (data *DataObject) AddSprite(sprite Sprite) {
+	// default anchor
+	if sprite.AnchorX == 0 && sprite.AnchorY == 0 {
+		sprite.AnchorX = 0.5
+		sprite.AnchorY = 0.5
+	}
+
+	// load texture (a Sprite without TextureSource brings its own Texture, see Framebuffer.AsSprite())
+	if sprite.TextureSource != "" {
+		sprite.Texture = data.loadTexture(sprite.TextureSource)
+	}
+
+	// load extra textures (copy the slice first, so we don't write into the caller's sprite)
+	sprite.ExtraTextures = append([]SpriteTexture(nil), sprite.ExtraTextures...)
+	for i := range sprite.ExtraTextures {
+		extra := &sprite.ExtraTextures[i]
+		extra.Texture = data.loadTexture(extra.TextureSource)
+	}
+
+	// add sprite to DataObject
+	data.Sprites = append(data.Sprites, sprite)
+}
+
+// Same as AddSprite(), for a list of Sprites. Sprites that share a spritesheet (or extra texture)
+// load it only once, also within the list.
+func (data *DataObject) AddSprites(sprites []Sprite) {
+	// make room for all of them at once
+	if free := cap(data.Sprites) - len(data.Sprites); free < len(sprites) {
+		grown := make([]Sprite, len(data.Sprites), len(data.Sprites)+len(sprites))
+		copy(grown, data.Sprites)
+		data.Sprites = grown
+	}
+
+	for _, sprite := range sprites {
+		data.AddSprite(sprite)
+	}
+}
+
+// Returns the texture for the image file, loading it only if this DataObject didn't load it before.
+func (data *DataObject) loadTexture(source string) TextureID {
 	// initialize map
 	if data.Textures == nil {
 		data.Textures = make(map[string]TextureID)
 	}
 
-	// load texture
-	textureID := data.Textures[sprite.TextureSource]
+	textureID := data.Textures[source]
 	if textureID == 0 {
-		textureID = LoadImageToTexture(sprite.TextureSource)
-		data.Textures[sprite.TextureSource] = textureID
+		textureID = LoadImageToTexture(source)
+		data.Textures[source] = textureID
 	}
-	sprite.Texture = textureID
+	return textureID
+}
 
-	// add sprite to DataObject
-	data.Sprites = append(data.Sprites, sprite)
+// Replaces the spritesheet of the Sprite (e.g. for an outfit change), loading it into the DataObject's
+// texture cache if it isn't in there yet. Call it on the Sprite in data.Sprites (data.Sprites[i].SetTexture(...)).
+// When no Sprite of the DataObject uses the old texture anymore, it is removed from the cache and deleted.
+// Textures the DataObject didn't load itself (e.g. a Framebuffer's, from AsSprite()) are left alone.
+func (sprite *Sprite) SetTexture(data *DataObject, newSource string) {
+	oldSource := sprite.TextureSource
+	oldTexture := sprite.Texture
+
+	sprite.TextureSource = newSource
+	sprite.Texture = data.loadTexture(newSource)
+
+	ownsOldTexture := oldSource != "" && data.Textures[oldSource] == oldTexture
+	if ownsOldTexture && oldTexture != 0 && oldTexture != sprite.Texture && !data.usesTexture(oldTexture) {
+		delete(data.Textures, oldSource)
+		deleteTexture(oldTexture)
+	}
+}
+
+// Returns true when one of the DataObject's Sprites uses the texture, as spritesheet or as extra texture.
+func (data *DataObject) usesTexture(texture TextureID) bool {
+	for _, sprite := range data.Sprites {
+		if sprite.Texture == texture {
+			return true
+		}
+		for _, extra := range sprite.ExtraTextures {
+			if extra.Texture == texture {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Return the requested sprite from the sprite list, and bind its texture.
@@ -45,8 +126,13 @@ func (data *DataObject) SelectSprite(spriteIndex int) *Sprite {
 	// Get Sprite as pointer
 	sprite := &data.Sprites[spriteIndex]
 
-	// Bind the Sprite's texture to TEXTURE_2D
-	gl.BindTexture(gl.TEXTURE_2D, uint32(sprite.Texture))
+	// Bind the extra textures to their own units (1, 2, ...)
+	for i, extra := range sprite.ExtraTextures {
+		BindTextureUnit(extra.Texture, uint32(i+1))
+	}
+
+	// Bind the Sprite's texture to TEXTURE_2D on the default unit
+	BindTextureUnit(sprite.Texture, 0)
 
 	return sprite
 }
@@ -69,23 +155,61 @@ func (sprite *Sprite) Update() {
 	}
 }
 
+// Sets Xn and Yn from a position in pixels (origin at the top-left of the screen, like the cursor position).
+// screenW and screenH are the size of the screen (viewport) in pixels.
+// x, y and the screen size have to be in the same units: on HiDPI screens, pass the cursor position
+// together with WindowSize(), and pixel positions together with FramebufferSize() (see FramebufferScale()).
+func (sprite *Sprite) SetScreenPos(x, y float32, screenW, screenH int) {
+	sprite.Xn = 2*x/float32(screenW) - 1
+	sprite.Yn = 1 - 2*y/float32(screenH)
+}
+
+// Returns the position of the sprite in pixels (origin at the top-left of the screen).
+// This is the inverse of SetScreenPos(), and the result is in the same units as screenW and screenH.
+func (sprite *Sprite) ScreenPos(screenW, screenH int) (float32, float32) {
+	x := (sprite.Xn + 1) / 2 * float32(screenW)
+	y := (1 - sprite.Yn) / 2 * float32(screenH)
+	return x, y
+}
+
 // Sets all the uniforms that apply to the Sprite, so that the shaders know what to do.
 func (sprite *Sprite) SetUniforms(data *DataObject) {
 
-	// Set the divisions uniform (used to locate the correct tile on the texture)
-	data.Program.SetFloat("tex_divisions", float32(sprite.Divisions))
-
-	// Set the position of the Sprite tile on the Texture
-	data.Program.SetFloat("tex_x", sprite.AnimationFrames[sprite.CurrentFrame][0])
-	data.Program.SetFloat("tex_y", sprite.AnimationFrames[sprite.CurrentFrame][1])
+	frame := sprite.AnimationFrames[sprite.CurrentFrame]
+	if sprite.UseAtlasRects {
+		// Set the rectangle of the frame on the Texture directly, as (u0, v0, u1, v1) in texture coordinates
+		data.Program.SetFloatVector4("tex_rect", &[4]float32{frame[0], frame[1], frame[2], frame[3]})
+	} else {
+		// Set the divisions uniform (used to locate the correct tile on the texture)
+		data.Program.SetFloat("tex_divisions", float32(sprite.Divisions))
+
+		// Set the position of the Sprite tile on the Texture
+		data.Program.SetFloat("tex_x", frame[0])
+		data.Program.SetFloat("tex_y", frame[1])
+	}
 
 	// Set the (normalized) position of the Sprite on the screen
 	data.Program.SetFloat("x", sprite.Xn)
 	data.Program.SetFloat("y", sprite.Yn)
 
+	// Set the depth, the shader should write it into gl_Position.z.
+	// With EnableDepthTest() the depth buffer then sorts out which sprite is in front, regardless of draw order.
+	// Note that this only works well for opaque pixels: a transparent pixel still writes depth and hides what is
+	// drawn behind it later. So draw transparent sprites back to front (or discard fully transparent pixels in the shader).
+	data.Program.SetFloat("z", sprite.Z)
+
 	// Used for zooming, a bit hacky, should rewrite with matrix manipulation or something.
 	data.Program.SetFloat("scale", sprite.Scale)
 
 	// Flip the texture tile horizontally or not (1.0 for yes, 0.0 for no)
 	data.Program.SetFloat("tex_fliph", sprite.FlipHorizontal)
+
+	// The point of the quad that (x, y) refers to, and that scaling happens around
+	data.Program.SetFloat("anchor_x", sprite.AnchorX)
+	data.Program.SetFloat("anchor_y", sprite.AnchorY)
+
+	// Point the sampler uniforms of the extra textures to the units SelectSprite() bound them to
+	for i, extra := range sprite.ExtraTextures {
+		data.Program.SetInt(extra.Uniform, int32(i+1))
+	}
 }
@@ -0,0 +1,138 @@
+package gogl
+
+/*
+	STREAM BUFFER
+
+	For vertex data that changes every frame, even UpdateVertices() can stall:
+	the driver has to wait until the GPU is done with the previous contents
+	before it can overwrite them. A StreamBuffer avoids that by keeping the
+	buffer mapped (glBufferStorage with GL_MAP_PERSISTENT_BIT, GL 4.4+) and
+	cycling through three sections: while the GPU draws from one, we write into
+	the next. A fence per section tells us when the GPU is done with it.
+
+	Usage, after ProcessData():
+		data.EnableStreaming(maxVertices)
+		// every frame:
+		vertices := data.StreamVertices()
+		n := copy(vertices, myVertices) // write the vertices directly into the buffer
+		data.Enable()
+		data.DrawStreamed(n / 4)        // number of vertices, here in the x, y, u, v layout
+*/
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Number of sections a StreamBuffer cycles through (triple buffering)
+const streamSections = 3
+
+type StreamBuffer struct {
+	ID          BufferID                // id of the buffer object
+	SectionSize int                     // Number of float32s in one section
+	current     int                     // Section that is written and drawn this frame
+	mapped      []float32               // The whole mapped buffer, all sections after each other
+	fences      [streamSections]uintptr // Fence per section, set after drawing from it, 0 when the section is free
+}
+
+// Creates a persistently mapped buffer with room for sectionSize float32s per section.
+func NewStreamBuffer(sectionSize int) *StreamBuffer {
+	stream := &StreamBuffer{SectionSize: sectionSize}
+
+	stream.ID = GenBuffer(gl.ARRAY_BUFFER)
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(stream.ID))
+
+	// Coherent: our writes become visible to the GPU without flushing them by hand
+	flags := uint32(gl.MAP_WRITE_BIT | gl.MAP_PERSISTENT_BIT | gl.MAP_COHERENT_BIT)
+	byteSize := 4 * sectionSize * streamSections
+	gl.BufferStorage(gl.ARRAY_BUFFER, byteSize, nil, flags)
+	pointer := gl.MapBufferRange(gl.ARRAY_BUFFER, 0, byteSize, flags)
+	stream.mapped = unsafe.Slice((*float32)(pointer), sectionSize*streamSections)
+
+	return stream
+}
+
+// Waits until the GPU is done with the current section, and returns it to write into.
+func (stream *StreamBuffer) Section() []float32 {
+	if fence := stream.fences[stream.current]; fence != 0 {
+		for {
+			result := gl.ClientWaitSync(fence, gl.SYNC_FLUSH_COMMANDS_BIT, uint64(1e6)) // 1ms
+			if result == gl.ALREADY_SIGNALED || result == gl.CONDITION_SATISFIED || result == gl.WAIT_FAILED {
+				break
+			}
+		}
+		gl.DeleteSync(fence)
+		stream.fences[stream.current] = 0
+	}
+	start := stream.current * stream.SectionSize
+	return stream.mapped[start : start+stream.SectionSize]
+}
+
+// Returns the offset of the current section in float32s.
+func (stream *StreamBuffer) SectionOffset() int {
+	return stream.current * stream.SectionSize
+}
+
+// Marks the current section as in use by the GPU (call it right after drawing from it),
+// and moves on to the next section.
+func (stream *StreamBuffer) Advance() {
+	stream.fences[stream.current] = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	stream.current = (stream.current + 1) % streamSections
+}
+
+// Unmaps and deletes the buffer.
+func (stream *StreamBuffer) Delete() {
+	for i, fence := range stream.fences {
+		if fence != 0 {
+			gl.DeleteSync(fence)
+			stream.fences[i] = 0
+		}
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(stream.ID))
+	gl.UnmapBuffer(gl.ARRAY_BUFFER)
+	bufferID := uint32(stream.ID)
+	gl.DeleteBuffers(1, &bufferID)
+	stream.mapped = nil
+	stream.ID = 0
+}
+
+/*
+Replaces the VBO of the DataObject by a StreamBuffer with room for maxVertices vertices per frame.
+Call it after ProcessData(). From then on, write the vertices into StreamVertices() and draw them with
+DrawStreamed(), instead of using Vertices/UpdateVertices(). Indices (for the indexed types)
+keep working as usual, and index the vertices of the current frame.
+*/
+func (data *DataObject) EnableStreaming(maxVertices int) {
+	stride := int(layoutStride(data.vertexLayout()))
+	data.Stream = NewStreamBuffer(maxVertices * stride)
+
+	// Point the attributes at the stream buffer instead of the VBO
+	oldVBOID := uint32(data.VBOID)
+	gl.DeleteBuffers(1, &oldVBOID)
+	data.VBOID = data.Stream.ID
+	data.vertexCapacity = 0
+	data.setupAttributes()
+	gl.BindVertexArray(0)
+}
+
+// Returns the part of the StreamBuffer to write this frame's vertices into, waiting for the GPU if it
+// is still drawing from it. Don't keep the slice around after calling DrawStreamed().
+func (data *DataObject) StreamVertices() []float32 {
+	return data.Stream.Section()
+}
+
+// Draws the first vertexCount vertices of this frame's StreamVertices(), and moves on to the next
+// section of the StreamBuffer. Call Enable() first.
+func (data *DataObject) DrawStreamed(vertexCount int) {
+	stride := int(layoutStride(data.vertexLayout()))
+	firstVertex := int32(data.Stream.SectionOffset() / stride)
+
+	if data.usesIndices() {
+		gl.DrawElementsBaseVertex(gl.TRIANGLES, data.indexCount(), data.indexType(), nil, firstVertex)
+	} else {
+		gl.DrawArrays(data.primitiveMode(), firstVertex, int32(vertexCount))
+	}
+
+	data.Stream.Advance()
+}
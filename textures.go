@@ -1,12 +1,15 @@
 package gogl
 
 import (
+	"fmt"
 	//"time"
 
+	"log"
 	"os"
 
 	//"io/ioutil"
-	//"log"
+	"image"
+	"image/color"
 	"image/png"
 
 	"github.com/go-gl/gl/v4.5-core/gl"
@@ -14,67 +17,552 @@ import (
 
 type TextureID uint32
 
+// A texture together with its size. LoadTexture() returns this, LoadImageToTexture() just the ID.
+type Texture struct {
+	ID     TextureID
+	Width  int // in pixels
+	Height int // in pixels
+}
+
+// Size (width, height) of every texture we created, so we can always look it up from just the ID.
+var textureSizes = make(map[TextureID][2]int)
+
+// Format of the pixel data of every texture we created (gl.RGBA, gl.RED, or a compressed format for DDS textures),
+// so UpdateTextureRegion() knows what to expect.
+var textureFormats = make(map[TextureID]uint32)
+
+// Returns the Texture (id and size) for a TextureID created by gogl. Width and Height are 0 when unknown.
+func GetTexture(id TextureID) Texture {
+	size := textureSizes[id]
+	return Texture{ID: id, Width: size[0], Height: size[1]}
+}
+
 func LoadPixelDataFromImage(filename string) (*[]byte, [2]int) {
-	file, err := os.Open(filename)
+	pixels, dimensions, err := loadPixelData(filename, true)
 	if err != nil {
 		panic(err)
 	}
+	return &pixels, dimensions
+}
+
+// Reads and decodes the png file.
+func loadImage(filename string) (image.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
 
 	img, err := png.Decode(file)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return img, nil
+}
+
+// Same as LoadPixelDataFromImage(), but returns an error instead of panicking.
+// When flip is false, the rows are kept in image order (top to bottom).
+func loadPixelData(filename string, flip bool) ([]byte, [2]int, error) {
+	img, err := loadImage(filename)
+	if err != nil {
+		return nil, [2]int{}, err
+	}
+
+	pixels, dimensions := pixelDataFromImage(img, flip)
+	return pixels, dimensions, nil
+}
+
+// Same as loadPixelData(), but keeps 8-bit grayscale images (image.Gray) single-channel instead of
+// expanding them to RGBA. Also returns the format of the pixel data: gl.RED or gl.RGBA.
+func loadTexturePixels(filename string, flip bool) ([]byte, [2]int, uint32, error) {
+	img, err := loadImage(filename)
+	if err != nil {
+		return nil, [2]int{}, 0, err
+	}
+
+	if grayImg, ok := img.(*image.Gray); ok {
+		pixels, dimensions := pixelDataFromGrayImage(grayImg, flip)
+		return pixels, dimensions, gl.RED, nil
 	}
+	pixels, dimensions := pixelDataFromImage(img, flip)
+	return pixels, dimensions, gl.RGBA, nil
+}
+
+// Copies the gray values of the image, 1 byte per pixel, with the row order as in pixelDataFromImage().
+func pixelDataFromGrayImage(img *image.Gray, flip bool) ([]byte, [2]int) {
+	bounds := img.Bounds()
+	w := bounds.Dx()
+	h := bounds.Dy()
+	pixels := make([]byte, w*h)
 
-	w := img.Bounds().Max.X
-	h := img.Bounds().Max.Y
+	for y := 0; y < h; y++ {
+		srcStart := img.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		dstStart := y * w
+		if flip {
+			dstStart = (h - 1 - y) * w
+		}
+		copy(pixels[dstStart:dstStart+w], img.Pix[srcStart:srcStart+w])
+	}
+	return pixels, [2]int{w, h}
+}
 
+// Converts the image to RGBA bytes, with the rows ordered bottom to top (GL orientation), or
+// top to bottom (image orientation) when flip is false.
+// The color values are straight (not premultiplied by alpha), which is what EnableBlending() expects.
+func pixelDataFromImage(img image.Image, flip bool) ([]byte, [2]int) {
+	bounds := img.Bounds()
+	w := bounds.Dx()
+	h := bounds.Dy()
 	pixels := make([]byte, w*h*4)
-	byteIndex := 0
+	rowLength := w * 4
+
+	// Row y of the image ends up at row dstRow(y) of the pixel data
+	dstRow := func(y int) int {
+		if flip {
+			return h - 1 - y
+		}
+		return y
+	}
+
+	// Fast paths: the decoder already gave us RGBA bytes, so we can copy whole rows at once
+	switch typedImg := img.(type) {
+	case *image.NRGBA:
+		// Straight alpha already (typical for png's with transparency)
+		for y := 0; y < h; y++ {
+			srcStart := typedImg.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			dstStart := dstRow(y) * rowLength
+			copy(pixels[dstStart:dstStart+rowLength], typedImg.Pix[srcStart:srcStart+rowLength])
+		}
+		return pixels, [2]int{w, h}
+
+	case *image.RGBA:
+		// Premultiplied alpha, so after copying we still have to divide the colors by alpha
+		for y := 0; y < h; y++ {
+			srcStart := typedImg.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			dstStart := dstRow(y) * rowLength
+			copy(pixels[dstStart:dstStart+rowLength], typedImg.Pix[srcStart:srcStart+rowLength])
+		}
+		unpremultiplyPixels(pixels)
+		return pixels, [2]int{w, h}
+	}
 
-	for y := h - 1; y >= 0; y-- {
-		for x := 0; x < w; x++ {
-			r, g, b, a := img.At(x, y).RGBA()
-			pixels[byteIndex] = byte(r / 256)
+	// Generic path for all other image types.
+	// Note that color.RGBA() returns premultiplied values, so we convert to NRGBA instead.
+	byteIndex := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pixels[byteIndex] = c.R
 			byteIndex++
-			pixels[byteIndex] = byte(g / 256)
+			pixels[byteIndex] = c.G
 			byteIndex++
-			pixels[byteIndex] = byte(b / 256)
+			pixels[byteIndex] = c.B
 			byteIndex++
-			pixels[byteIndex] = byte(a / 256)
+			pixels[byteIndex] = c.A
 			byteIndex++
 		}
 	}
 
-	return &pixels, [2]int{w, h}
+	// Images start at the top-left, GL textures at the bottom-left
+	if flip {
+		flipPixelRows(pixels, rowLength)
+	}
+
+	return pixels, [2]int{w, h}
+}
+
+// Converts premultiplied RGBA bytes to straight alpha, in place.
+// Rounds the same way as color.NRGBAModel, so the *image.RGBA fast path gives the same bytes as the generic path.
+func unpremultiplyPixels(pixels []byte) {
+	for i := 0; i+3 < len(pixels); i += 4 {
+		a := uint32(pixels[i+3])
+		if a == 255 {
+			continue
+		}
+		if a == 0 {
+			pixels[i], pixels[i+1], pixels[i+2] = 0, 0, 0
+			continue
+		}
+		for c := i; c < i+3; c++ {
+			pixels[c] = uint8((uint32(pixels[c]) * 0x101 * 0xffff / (a * 0x101)) >> 8)
+		}
+	}
+}
+
+// Reverses the order of the rows in the pixel data, in place.
+// Used to convert between image orientation (top-left origin) and GL orientation (bottom-left origin).
+// rowLength is the number of bytes in one row (width * bytes per pixel).
+func flipPixelRows(pixels []byte, rowLength int) {
+	tmp := make([]byte, rowLength)
+	rows := len(pixels) / rowLength
+	for top, bottom := 0, rows-1; top < bottom; top, bottom = top+1, bottom-1 {
+		topRow := pixels[top*rowLength : (top+1)*rowLength]
+		bottomRow := pixels[bottom*rowLength : (bottom+1)*rowLength]
+		copy(tmp, topRow)
+		copy(topRow, bottomRow)
+		copy(bottomRow, tmp)
+	}
+}
+
+// Options for loading textures, see LoadImageToTextureWithOptions().
+// Start from DefaultTextureOptions() and change what you need.
+type TextureOptions struct {
+	SRGB        bool    // Store the texture as sRGB, so the GPU converts it to linear when sampling. Use together with EnableSRGBFramebuffer().
+	Anisotropy  float32 // Anisotropic filtering level (e.g. 4, 16), sharpens textures seen at an angle. 0 or 1 is off. Clamped to what the GPU supports.
+	NoFlip      bool    // Keep the rows in image orientation (top-left origin), instead of flipping them to GL orientation (bottom-left origin). For images that are stored in GL orientation already.
+	LODBias     float32 // Shifts which mipmap level is sampled, negative values pick sharper (bigger) levels. 0 is no bias. See SetTextureLOD().
+	MaxMipLevel int     // The smallest mipmap level that may be sampled (level 0 is the full image, every level halves the size). 0 is no limit. See SetTextureLOD().
+}
+
+// From the anisotropic filtering extensions (GL_EXT_texture_filter_anisotropic, GL_ARB_texture_filter_anisotropic),
+// which are not part of the 4.5 core profile.
+const (
+	glTEXTURE_MAX_ANISOTROPY     = 0x84FE
+	glMAX_TEXTURE_MAX_ANISOTROPY = 0x84FF
+)
+
+// Returns the options LoadImageToTexture() uses.
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{}
+}
+
+// Loads the image into a texture, and returns the texture with its size.
+func LoadTexture(filename string) Texture {
+	return GetTexture(LoadImageToTexture(filename))
+}
+
+// Same as LoadTexture(), with custom options.
+func LoadTextureWithOptions(filename string, options TextureOptions) Texture {
+	return GetTexture(LoadImageToTextureWithOptions(filename, options))
 }
 
+// Loads the image into a texture, and returns just its ID. Use LoadTexture() if you need the size,
+// or look it up later with GetTexture().
 func LoadImageToTexture(filename string) TextureID {
+	return LoadImageToTextureWithOptions(filename, DefaultTextureOptions())
+}
+
+// 8-bit grayscale images (e.g. heightmaps and masks) are stored as a single-channel gl.RED texture,
+// which takes a quarter of the memory. Sample .r in the shader for those.
+func LoadImageToTextureWithOptions(filename string, options TextureOptions) TextureID {
+
+	pixels, dimensions, format, err := loadTexturePixels(filename, !options.NoFlip)
+	if err != nil {
+		if UseMissingTexture {
+			log.Printf("Warning: could not load texture, using the missing texture instead: %s \n", err)
+
+			// Own copy of the checkerboard, so HotloadTextures() can load the file into it once it is fixed,
+			// without touching the shared MissingTexture()
+			texId := newMissingTexture()
+			addPlaceholderTextureToWatchList(filename, texId, options)
+			return texId
+		}
+		panic(err)
+	}
+	texId := uploadTexture(pixels, dimensions, format, options)
+
+	// Track the file, so HotloadTextures() can reload it when it changes
+	addTextureToWatchList(filename, texId, options)
+
+	return texId
+}
+
+// Creates a texture from RGBA pixels computed in Go (e.g. noise or gradients), with the same
+// wrap and filter settings as LoadImageToTexture(). pixels holds width*height*4 bytes, rows ordered
+// bottom to top (GL orientation), so no flipping is done.
+func TextureFromRGBA(pixels []byte, width, height int) (TextureID, error) {
+	if width <= 0 || height <= 0 {
+		return 0, fmt.Errorf("invalid texture size %dx%d", width, height)
+	}
+	if len(pixels) != width*height*4 {
+		return 0, fmt.Errorf("got %d bytes, need %d for a %dx%d RGBA texture", len(pixels), width*height*4, width, height)
+	}
+	return uploadTexture(pixels, [2]int{width, height}, gl.RGBA, DefaultTextureOptions()), nil
+}
 
-	pixels, dimensions := LoadPixelDataFromImage(filename)
+// When true, textures that fail to load (e.g. because the path is wrong) are replaced by the
+// MissingTexture() checkerboard and a warning is logged, instead of panicking. The file is still watched,
+// so HotloadTextures() loads it as soon as it is created or fixed. Off by default.
+var UseMissingTexture bool
 
+// Shared missing texture, created on first use.
+var missingTexture TextureID
+
+// Size of the missing texture, and of the checkers on it, in pixels
+const (
+	missingTextureSize   = 64
+	missingTextureSquare = 8
+)
+
+// Returns a magenta/black checkerboard texture, the stand-in for textures that failed to load
+// (see UseMissingTexture). It is created once and shared, so don't change or delete it.
+func MissingTexture() TextureID {
+	if missingTexture == 0 {
+		missingTexture = newMissingTexture()
+	}
+	return missingTexture
+}
+
+// Creates a new texture with the missing texture checkerboard on it.
+func newMissingTexture() TextureID {
+	pixels := make([]byte, missingTextureSize*missingTextureSize*4)
+	for y := 0; y < missingTextureSize; y++ {
+		for x := 0; x < missingTextureSize; x++ {
+			i := (y*missingTextureSize + x) * 4
+			if (x/missingTextureSquare+y/missingTextureSquare)%2 == 0 {
+				pixels[i] = 255   // r
+				pixels[i+2] = 255 // b
+			}
+			pixels[i+3] = 255 // a
+		}
+	}
+
+	texId := uploadTexture(pixels, [2]int{missingTextureSize, missingTextureSize}, gl.RGBA, DefaultTextureOptions())
+	// Keep the checkers crisp
+	SetTextureFilter(texId, gl.NEAREST_MIPMAP_NEAREST, gl.NEAREST)
+	return texId
+}
+
+// Solid color textures made so far, so every color is only uploaded once.
+var solidColorTextures = make(map[[4]byte]TextureID)
+
+// Returns a 1x1 texture of the given color, e.g. as placeholder art, or to draw flat colored quads with
+// a textured shader. The texture is created once per color and shared, so don't change or delete it.
+func SolidColorTexture(r, g, b, a byte) TextureID {
+	rgba := [4]byte{r, g, b, a}
+	if texId, ok := solidColorTextures[rgba]; ok {
+		return texId
+	}
+	texId := uploadTexture(rgba[:], [2]int{1, 1}, gl.RGBA, DefaultTextureOptions())
+	solidColorTextures[rgba] = texId
+	return texId
+}
+
+// Returns a shared 1x1 white texture. Sampling it gives 1 for every channel, so multiplying with it
+// (e.g. with a tint) leaves the other color as is.
+func WhiteTexture() TextureID {
+	return SolidColorTexture(255, 255, 255, 255)
+}
+
+// Creates a texture from pixel data (rows ordered bottom to top), with the default
+// wrap and filter settings, and generates its mipmaps.
+// format is the layout of the pixel data: gl.RGBA, or gl.RED for single-channel data.
+func uploadTexture(pixels []byte, dimensions [2]int, format uint32, options TextureOptions) TextureID {
 	texId := GenTexture()
 	BindTexture(texId)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	setTextureAnisotropy(options.Anisotropy)
+	setTextureLOD(options.LODBias, options.MaxMipLevel)
 
 	// Load image in texture
 	// target, level, colormode, width, heigth, border, format, xtype, *pixels
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(dimensions[0]), int32(dimensions[1]), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(*pixels))
+	texImage2D(pixels, dimensions, format, options)
 
 	// Prerender smaller versions of texture at runtime for performance reasons
 	gl.GenerateMipmap(gl.TEXTURE_2D)
 
+	textureSizes[texId] = dimensions
+	textureFormats[texId] = format
+
 	return texId
 }
 
+// Sets the anisotropic filtering level of the bound texture, if the extension is available.
+func setTextureAnisotropy(level float32) {
+	if level <= 1 {
+		return
+	}
+	if !HasExtension("GL_EXT_texture_filter_anisotropic") && !HasExtension("GL_ARB_texture_filter_anisotropic") {
+		return
+	}
+	var maxLevel float32
+	gl.GetFloatv(glMAX_TEXTURE_MAX_ANISOTROPY, &maxLevel)
+	if level > maxLevel {
+		level = maxLevel
+	}
+	gl.TexParameterf(gl.TEXTURE_2D, glTEXTURE_MAX_ANISOTROPY, level)
+}
+
+// Uploads the pixels to the bound TEXTURE_2D, in the format they are in (gl.RGBA or gl.RED).
+func texImage2D(pixels []byte, dimensions [2]int, format uint32, options TextureOptions) {
+	if format == gl.RED {
+		// Rows of single-channel data are not padded to 4 bytes
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		defer gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	}
+	gl.TexImage2D(gl.TEXTURE_2D, 0, textureInternalFormat(format, options), int32(dimensions[0]), int32(dimensions[1]), 0, format, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+}
+
+// Returns the format GL should store the texture in.
+// Single-channel (gl.RED) textures are always stored linear, as there is no single-channel sRGB format.
+func textureInternalFormat(format uint32, options TextureOptions) int32 {
+	if format == gl.RED {
+		return gl.R8
+	}
+	if options.SRGB {
+		return gl.SRGB8_ALPHA8
+	}
+	return gl.RGBA
+}
+
 func GenTexture() TextureID {
 	var texId uint32
 	gl.GenTextures(1, &texId)
 	return TextureID(texId)
 }
 
+// Removes the texture from GL, and stops watching its file for changes.
+// Shared textures (MissingTexture(), SolidColorTexture()) are never deleted, other DataObjects may still use them.
+func deleteTexture(tex TextureID) {
+	if isSharedTexture(tex) {
+		return
+	}
+	texID := uint32(tex)
+	gl.DeleteTextures(1, &texID)
+	delete(textureSizes, tex)
+	delete(textureFormats, tex)
+	removeTextureFromWatchList(tex)
+}
+
+// Returns true for the textures gogl hands out to everyone: MissingTexture() and the SolidColorTexture()s.
+func isSharedTexture(tex TextureID) bool {
+	if tex == missingTexture {
+		return true
+	}
+	for _, texId := range solidColorTextures {
+		if texId == tex {
+			return true
+		}
+	}
+	return false
+}
+
 func BindTexture(TexId TextureID) {
 	gl.BindTexture(gl.TEXTURE_2D, uint32(TexId))
 }
+
+// Makes the given texture unit active, and binds the texture to TEXTURE_2D on it.
+// Note that the unit stays active afterwards, so later BindTexture() calls also go to that unit.
+func BindTextureUnit(TexId TextureID, unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	BindTexture(TexId)
+}
+
+// Changes how the texture is sampled, e.g. gl.NEAREST for crisp pixel art when zooming in, or gl.LINEAR for smooth.
+// min is used when the texture is drawn smaller than its size, and can also be a mipmap filter
+// (e.g. gl.LINEAR_MIPMAP_LINEAR). mag is used when it's drawn bigger, and can only be gl.NEAREST or gl.LINEAR.
+// All Sprites that use the texture are affected.
+func SetTextureFilter(tex TextureID, min, mag int32) {
+	BindTexture(tex)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, min)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, mag)
+}
+
+// Sets the mipmap level of detail of the texture at runtime, e.g. against blurry pixel art when zoomed out a bit.
+// bias shifts which mipmap level is sampled, negative values pick sharper levels (-0.5 to -1 is typical).
+// maxLevel caps the mipmap chain at that level, so the texture is never sampled smaller than that; 0 is no limit.
+// Mipmaps are only used with a mipmap min filter, e.g. SetTextureFilter(tex, gl.LINEAR_MIPMAP_LINEAR, gl.LINEAR).
+func SetTextureLOD(tex TextureID, bias float32, maxLevel int) {
+	BindTexture(tex)
+	setTextureLOD(bias, maxLevel)
+}
+
+// Sets the lod bias and max mipmap level of the bound texture, see SetTextureLOD().
+func setTextureLOD(bias float32, maxLevel int) {
+	gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_LOD_BIAS, bias)
+	if maxLevel > 0 {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, int32(maxLevel))
+	} else {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 1000) // GL default
+	}
+}
+
+// Loads the images as the layers of a GL_TEXTURE_2D_ARRAY, in the given order. All images need
+// to have the same size. In the shader, use a sampler2DArray and pick the layer with the third
+// texture coordinate (e.g. the current animation frame), instead of doing spritesheet math.
+func LoadImagesToTextureArray(filenames []string) (TextureID, error) {
+	if len(filenames) == 0 {
+		return 0, fmt.Errorf("no images given for texture array")
+	}
+
+	// Load all images first, so we don't end up with half a texture on error
+	layers := make([][]byte, len(filenames))
+	var dimensions [2]int
+	for i, filename := range filenames {
+		pixels, layerDimensions, err := loadPixelData(filename, true)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 {
+			dimensions = layerDimensions
+		} else if layerDimensions != dimensions {
+			return 0, fmt.Errorf("%s is %dx%d, but %s is %dx%d; all layers must have the same size",
+				filename, layerDimensions[0], layerDimensions[1], filenames[0], dimensions[0], dimensions[1])
+		}
+		layers[i] = pixels
+	}
+
+	texId := GenTexture()
+	BindTextureArray(texId)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+
+	// Allocate all layers, then fill them one by one
+	// target, level, colormode, width, height, depth (layers), border, format, xtype, *pixels
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.RGBA, int32(dimensions[0]), int32(dimensions[1]), int32(len(layers)), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	for i, pixels := range layers {
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(i), int32(dimensions[0]), int32(dimensions[1]), 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	}
+
+	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+
+	return texId, nil
+}
+
+func BindTextureArray(TexId TextureID) {
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, uint32(TexId))
+}
+
+// Replaces a rectangle of the texture with the given pixels (rows bottom to top), without reallocating
+// the texture. The pixels are in the format of the texture: w*h*4 bytes for RGBA textures, w*h bytes
+// for single-channel (grayscale) ones. Compressed (DDS) textures can't be updated.
+// x, y is the bottom-left corner of the region, in pixels. Mipmaps are regenerated afterwards.
+func UpdateTextureRegion(tex TextureID, x, y, w, h int, pixels []byte) error {
+	size, ok := textureSizes[tex]
+	if !ok {
+		return fmt.Errorf("texture %d: size unknown, was it created by gogl?", tex)
+	}
+	if x < 0 || y < 0 || w <= 0 || h <= 0 || x+w > size[0] || y+h > size[1] {
+		return fmt.Errorf("texture %d: region (%d, %d, %dx%d) does not fit in %dx%d", tex, x, y, w, h, size[0], size[1])
+	}
+
+	format := textureFormats[tex]
+	var bytesPerPixel int
+	switch format {
+	case gl.RGBA:
+		bytesPerPixel = 4
+	case gl.RED:
+		bytesPerPixel = 1
+	default:
+		return fmt.Errorf("texture %d: compressed textures can't be updated", tex)
+	}
+	if len(pixels) < w*h*bytesPerPixel {
+		return fmt.Errorf("texture %d: got %d bytes, need %d for a %dx%d region", tex, len(pixels), w*h*bytesPerPixel, w, h)
+	}
+
+	BindTexture(tex)
+	if format == gl.RED {
+		// Rows of single-channel data are not padded to 4 bytes
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		defer gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	}
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(x), int32(y), int32(w), int32(h), format, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	gl.GenerateMipmap(gl.TEXTURE_2D)
+
+	return nil
+}
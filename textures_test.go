@@ -0,0 +1,172 @@
+package gogl
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Hides the concrete type of the image, so pixelDataFromImage() can't use a fast path for it.
+type opaqueImage struct {
+	image.Image
+}
+
+// An image with every (color, alpha) combination: x is the color value, y the alpha,
+// and the bounds don't start at 0, 0, like a SubImage().
+func allCombinationsImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(3, 5, 3+256, 5+256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.SetRGBA(3+x, 5+y, color.RGBA{R: uint8(x), G: uint8(255 - x), B: uint8(x / 2), A: uint8(y)})
+		}
+	}
+	return img
+}
+
+// The fast paths must give exactly the same pixels as the generic path, flipped or not.
+func TestPixelDataFromImageFastPaths(t *testing.T) {
+	rgba := allCombinationsImage()
+	nrgba := image.NewNRGBA(rgba.Bounds())
+	copy(nrgba.Pix, rgba.Pix) // same bytes, now read as straight alpha
+
+	images := map[string]image.Image{"RGBA": rgba, "NRGBA": nrgba}
+	for name, img := range images {
+		for _, flip := range []bool{false, true} {
+			fast, fastDimensions := pixelDataFromImage(img, flip)
+			generic, genericDimensions := pixelDataFromImage(opaqueImage{img}, flip)
+			if fastDimensions != genericDimensions {
+				t.Fatalf("%s, flip %v: dimensions %v, generic path gives %v", name, flip, fastDimensions, genericDimensions)
+			}
+			for i := range generic {
+				if fast[i] != generic[i] {
+					pixel := i / 4
+					t.Fatalf("%s, flip %v: pixel %d (x %d, row %d) channel %d is %d, generic path gives %d",
+						name, flip, pixel, pixel%256, pixel/256, i%4, fast[i], generic[i])
+				}
+			}
+		}
+	}
+}
+
+// The first row of the pixel data is the bottom row of the image when flipping, the top row otherwise.
+func TestPixelDataFromImageFlip(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 1, A: 255}) // top
+	img.SetNRGBA(0, 1, color.NRGBA{R: 2, A: 255}) // bottom
+
+	pixels, _ := pixelDataFromImage(img, true)
+	if pixels[0] != 2 || pixels[4] != 1 {
+		t.Errorf("flipped: got rows %d, %d, want 2, 1", pixels[0], pixels[4])
+	}
+	pixels, _ = pixelDataFromImage(img, false)
+	if pixels[0] != 1 || pixels[4] != 2 {
+		t.Errorf("not flipped: got rows %d, %d, want 1, 2", pixels[0], pixels[4])
+	}
+}
+
+// A 2048x2048 image with semi-transparent pixels, so unpremultiplying has work to do.
+func benchmarkImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2048, 2048))
+	for y := 0; y < 2048; y++ {
+		for x := 0; x < 2048; x++ {
+			alpha := uint8(x + y)
+			img.SetRGBA(x, y, color.RGBA{R: alpha / 2, G: alpha / 3, B: alpha / 4, A: alpha})
+		}
+	}
+	return img
+}
+
+// Compare the fast path for *image.RGBA with the generic one:
+// go test -run NONE -bench PixelDataFromImage -benchmem
+func BenchmarkPixelDataFromImage(b *testing.B) {
+	img := benchmarkImage()
+
+	b.Run("RGBA", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pixelDataFromImage(img, true)
+		}
+	})
+	b.Run("Generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pixelDataFromImage(opaqueImage{img}, true)
+		}
+	})
+}
+
+// Encodes the image as a png file in a temporary directory, and returns its path.
+func writeTestPNG(tb testing.TB, img image.Image) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "test.png")
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// The png decoder gives *image.RGBA for opaque images and *image.NRGBA for translucent ones,
+// so loading both from a file goes through both fast paths.
+func testPNGImages(size int) map[string]image.Image {
+	opaque := image.NewNRGBA(image.Rect(0, 0, size, size))
+	translucent := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			opaque.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255})
+			translucent.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: uint8(x + y)})
+		}
+	}
+	return map[string]image.Image{"Opaque": opaque, "Translucent": translucent}
+}
+
+// Loading a png must give the same pixels as decoding it and going through the generic path.
+func TestLoadPixelDataMatchesGenericPath(t *testing.T) {
+	for name, img := range testPNGImages(64) {
+		path := writeTestPNG(t, img)
+
+		pixels, dimensions, err := loadPixelData(path, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := loadImage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		generic, genericDimensions := pixelDataFromImage(opaqueImage{decoded}, true)
+
+		if dimensions != genericDimensions || string(pixels) != string(generic) {
+			t.Errorf("%s (%T): loaded pixels differ from the generic path", name, decoded)
+		}
+	}
+}
+
+// Load time of a 2048x2048 png with the fast paths ("After"), and with only the generic path ("Before"):
+// go test -run NONE -bench LoadPNG -benchmem
+func BenchmarkLoadPNG(b *testing.B) {
+	for name, img := range testPNGImages(2048) {
+		path := writeTestPNG(b, img)
+
+		b.Run(name+"/Before", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				decoded, err := loadImage(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				pixelDataFromImage(opaqueImage{decoded}, true)
+			}
+		})
+		b.Run(name+"/After", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := loadPixelData(path, true); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -20,6 +20,27 @@ type BufferID uint32 // Vertex/Element Buffer Object
 
 // Datatypes, used when setting DataObject (see program.go)
 const (
-	GOGL_TRIANGLES = 0
-	GOGL_QUADS     = 1
+	GOGL_TRIANGLES         = 0 // x, y, z per vertex, drawn as separate triangles
+	GOGL_QUADS             = 1 // x, y, u, v per vertex, triangles defined by Indices
+	GOGL_TRIANGLE_STRIP    = 2 // x, y, u, v per vertex, every vertex after the first two adds a triangle
+	GOGL_TRIANGLE_FAN      = 3 // x, y, u, v per vertex, all triangles share the first vertex
+	GOGL_POINTS            = 4 // x, y, size, r, g, b, a per point, see EnableProgramPointSize()
+	GOGL_MESH              = 5 // x, y, z, nx, ny, nz, u, v per vertex (position, normal, texcoord), triangles defined by Indices. See LoadOBJ().
+	GOGL_INDEXED_TRIANGLES = 6 // x, y, z per vertex, like GOGL_TRIANGLES, but triangles defined by Indices
+)
+
+// One attribute in a vertex layout: a DataObject's vertices consist of the attributes of its layout, in order.
+// E.g. the GOGL_MESH layout is position (3), normal (3), texcoord (2). See DataObject.Layout for custom layouts.
+type VertexAttrib struct {
+	Name     string // Name of the attribute in the shader, e.g. "normal"
+	Location uint32 // Location to use when the shader doesn't declare (or use) Name, e.g. 2 for `layout (location = 2) in vec3 normal;`
+	Size     int32  // Number of float32s, 1 to 4
+}
+
+// Fallback locations of the per-instance attributes of DrawInstanced(). The VAO keeps its attribute
+// pointers, so these must not overlap with the locations of the vertex layouts (0 to 2, see typeLayouts);
+// keep them free in custom layouts too.
+const (
+	instanceOffsetLocation = 4
+	instanceTintLocation   = 5
 )